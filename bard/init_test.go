@@ -27,6 +27,8 @@ func TestUnit(t *testing.T) {
 	suite := spec.New("libpak/bard", spec.Report(report.Terminal{}))
 	suite("Logger", testLogger)
 	suite("Formatter", testFormatter)
+	suite("RedactingWriter", testRedactingWriter)
+	suite("StructuredWriter", testStructuredWriter)
 	suite("Writer", testWriter)
 	suite.Run(t)
 }
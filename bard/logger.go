@@ -54,8 +54,81 @@ func WithDebug(writer io.Writer) Option {
 	}
 }
 
-// NewLoggerWithOptions create a new instance of Logger.  It configures the Logger with options.
+// Level is a threshold below which log messages are discarded.
+type Level int
+
+const (
+	// ErrorLevel only logs terminal errors.
+	ErrorLevel Level = iota
+
+	// WarnLevel logs headers, titles, and terminal errors.
+	WarnLevel
+
+	// InfoLevel logs everything but debug messages.  This is the default.
+	InfoLevel
+
+	// DebugLevel logs everything, including debug messages.
+	DebugLevel
+)
+
+// WithLevel configures the Logger to discard all messages below the given level.  Writers that are below the level
+// are replaced with io.Discard.  DebugLevel writes debug messages to the info writer unless a debug writer has already
+// been configured with WithDebug, which is kept at all levels.
+func WithLevel(level Level) Option {
+	return func(logger Logger) Logger {
+		if level >= DebugLevel && !logger.IsDebugEnabled() {
+			logger.Logger = poet.NewLoggerWithOptions(logger.InfoWriter(), poet.WithDebug(logger.InfoWriter()))
+		}
+
+		if level < InfoLevel {
+			logger.Logger = poet.NewLoggerWithOptions(io.Discard, poet.WithDebug(logger.DebugWriter()))
+			logger.body = io.Discard
+		}
+
+		if level < WarnLevel {
+			logger.header = io.Discard
+			logger.title = io.Discard
+		}
+
+		return logger
+	}
+}
+
+// WithSecrets configures the Logger to replace each of the secrets with Redacted in all of its writers.  This option
+// should be applied after any other options that configure writers.  Output that ends with the beginning of a secret
+// is held back until the next write, see RedactingWriter.
+func WithSecrets(secrets ...string) Option {
+	return func(logger Logger) Logger {
+		redact := func(writer io.Writer) io.Writer {
+			if writer == nil {
+				return nil
+			}
+			return NewRedactingWriter(writer, secrets...)
+		}
+
+		var options []poet.Option
+		if logger.IsDebugEnabled() {
+			options = append(options, poet.WithDebug(redact(logger.DebugWriter())))
+		}
+		logger.Logger = poet.NewLoggerWithOptions(redact(logger.InfoWriter()), options...)
+
+		logger.body = redact(logger.body)
+		logger.header = redact(logger.header)
+		logger.terminalBody = redact(logger.terminalBody)
+		logger.terminalHeader = redact(logger.terminalHeader)
+		logger.title = redact(logger.title)
+
+		return logger
+	}
+}
+
+// NewLoggerWithOptions create a new instance of Logger.  It configures the Logger with options.  Color codes are
+// removed from the output if the writer does not support color.
 func NewLoggerWithOptions(writer io.Writer, options ...Option) Logger {
+	if !IsColorSupported(writer) {
+		writer = NewColorStrippingWriter(writer)
+	}
+
 	l := Logger{
 		Logger:         poet.NewLogger(writer),
 		body:           NewWriter(writer, WithAttributes(color.Faint), WithIndent(2)),
@@ -76,22 +149,73 @@ func NewLoggerWithOptions(writer io.Writer, options ...Option) Logger {
 func NewLogger(writer io.Writer) Logger {
 	var options []Option
 
+	debug := writer
+	if !IsColorSupported(writer) {
+		debug = NewColorStrippingWriter(writer)
+	}
+
 	// check for presence and value of log level environment variable
-	options = LogLevel(options, writer)
+	options = LogLevel(options, debug)
 
 	return NewLoggerWithOptions(writer, options...)
 }
 
+// NewLoggerTee creates a new instance of Logger that writes every message to all of the writers.  Color codes are
+// removed from the output of each writer that does not support color.  It configures debug logging if $BP_DEBUG is set.
+func NewLoggerTee(writers ...io.Writer) Logger {
+	var w []io.Writer
+	for _, writer := range writers {
+		if !IsColorSupported(writer) {
+			writer = NewColorStrippingWriter(writer)
+		}
+		w = append(w, writer)
+	}
+
+	return NewLogger(io.MultiWriter(w...))
+}
+
+// NewStructuredLogger creates a new instance of Logger that writes each message as a JSON line containing the level,
+// phase, message, and timestamp.  Color attributes are dropped.  It configures debug logging if $BP_DEBUG is set.
+func NewStructuredLogger(writer io.Writer) Logger {
+	l := Logger{
+		Logger:         poet.NewLoggerWithOptions(NewStructuredWriter(writer, "info", "info")),
+		body:           NewStructuredWriter(writer, "info", "body"),
+		header:         NewStructuredWriter(writer, "info", "header"),
+		terminalBody:   NewStructuredWriter(writer, "error", "terminal-error"),
+		terminalHeader: NewStructuredWriter(writer, "error", "terminal-error"),
+		title:          NewStructuredWriter(writer, "info", "title"),
+	}
+
+	for _, option := range LogLevel(nil, NewStructuredWriter(writer, "debug", "debug")) {
+		l = option(l)
+	}
+
+	return l
+}
+
+// LogLevel appends the options configured by $BP_DEBUG and $BP_LOG_LEVEL.  A $BP_LOG_LEVEL of "error" or "warn"
+// discards messages below that level, while "debug" (or the presence of $BP_DEBUG) enables the debug writer.
 func LogLevel(options []Option, writer io.Writer) []Option {
 
 	// Check for older log level env variable
 	_, dbSet := os.LookupEnv("BP_DEBUG")
 
+	level, _ := os.LookupEnv("BP_LOG_LEVEL")
+	level = strings.ToLower(level)
+
 	// Then check for common buildpack log level env variable - if either are set to DEBUG/true, enable Debug Writer
-	if level, ok := os.LookupEnv("BP_LOG_LEVEL"); (ok && strings.ToLower(level) == "debug") || dbSet {
+	if level == "debug" || dbSet {
 
 		options = append(options, WithDebug(writer))
 	}
+
+	switch level {
+	case "error":
+		options = append(options, WithLevel(ErrorLevel))
+	case "warn":
+		options = append(options, WithLevel(WarnLevel))
+	}
+
 	return options
 }
 
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/buildpacks/libcnb"
@@ -81,6 +83,206 @@ func testLogger(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("with NO_COLOR", func() {
+		it.Before(func() {
+			Expect(os.Setenv("NO_COLOR", "1")).To(Succeed())
+			l = bard.NewLogger(b)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("NO_COLOR")).To(Succeed())
+		})
+
+		it("writes body log without color", func() {
+			l.Body("test-message")
+			Expect(b.String()).To(Equal("    test-message\n"))
+		})
+	})
+
+	context("with BP_LOG_LEVEL set to WARN", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_LOG_LEVEL", "WARN")).To(Succeed())
+			l = bard.NewLogger(b)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LOG_LEVEL")).To(Succeed())
+		})
+
+		it("discards body and info", func() {
+			l.Body("test-body")
+			l.Info("test-info")
+			Expect(b.String()).To(Equal(""))
+		})
+
+		it("writes header and terminal error", func() {
+			l.Header("test-header")
+			l.TerminalError(bard.IdentifiableError{Name: "test-name", Err: fmt.Errorf("test-error")})
+			Expect(b.String()).To(ContainSubstring("test-header"))
+			Expect(b.String()).To(ContainSubstring("test-error"))
+		})
+	})
+
+	context("with BP_LOG_LEVEL set to ERROR", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_LOG_LEVEL", "ERROR")).To(Succeed())
+			l = bard.NewLogger(b)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LOG_LEVEL")).To(Succeed())
+		})
+
+		it("discards body, header, and title", func() {
+			l.Body("test-body")
+			l.Header("test-header")
+			l.Title(libcnb.Buildpack{Info: libcnb.BuildpackInfo{Name: "test-name"}})
+			Expect(b.String()).To(Equal(""))
+		})
+
+		it("writes terminal error", func() {
+			l.TerminalError(bard.IdentifiableError{Name: "test-name", Err: fmt.Errorf("test-error")})
+			Expect(b.String()).To(ContainSubstring("test-error"))
+		})
+	})
+
+	context("WithLevel", func() {
+		it("keeps debug writer", func() {
+			l = bard.NewLoggerWithOptions(b, bard.WithDebug(b), bard.WithLevel(bard.ErrorLevel))
+
+			l.Debug("test-debug")
+			l.Info("test-info")
+			Expect(b.String()).To(Equal("test-debug\n"))
+		})
+
+		it("enables debug writer at debug level", func() {
+			l = bard.NewLoggerWithOptions(b, bard.WithLevel(bard.DebugLevel))
+
+			l.Debug("test-debug")
+			l.Info("test-info")
+			Expect(b.String()).To(Equal("test-debug\ntest-info\n"))
+		})
+
+		it("does not write debug messages at info level", func() {
+			l = bard.NewLoggerWithOptions(b, bard.WithLevel(bard.InfoLevel))
+
+			l.Debug("test-debug")
+			Expect(l.IsDebugEnabled()).To(BeFalse())
+			Expect(b.String()).To(BeEmpty())
+		})
+
+		it("writes everything at info level", func() {
+			l = bard.NewLoggerWithOptions(b, bard.WithLevel(bard.InfoLevel))
+
+			l.Header("test-header")
+			l.Info("test-info")
+			Expect(b.String()).To(Equal("  test-header\ntest-info\n"))
+		})
+	})
+
+	context("tee", func() {
+		var f *os.File
+
+		it.Before(func() {
+			var err error
+			f, err = os.Create(filepath.Join(t.TempDir(), "log"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.Setenv("BP_DEBUG", "")).To(Succeed())
+			l = bard.NewLoggerTee(b, f)
+		})
+
+		it.After(func() {
+			Expect(f.Close()).To(Succeed())
+			Expect(os.Unsetenv("BP_DEBUG")).To(Succeed())
+		})
+
+		it("writes to all writers", func() {
+			l.Body("test-message")
+			l.Debug("test-debug")
+
+			Expect(b.String()).To(Equal("\x1b[2m    test-message\x1b[0m\ntest-debug\n"))
+			Expect(os.ReadFile(f.Name())).To(Equal([]byte("    test-message\ntest-debug\n")))
+		})
+	})
+
+	context("WithSecrets", func() {
+		it.Before(func() {
+			l = bard.NewLoggerWithOptions(b, bard.WithDebug(b), bard.WithSecrets("test-secret"))
+		})
+
+		it("masks secrets in all writers", func() {
+			l.Body("body test-secret")
+			l.Header("header test-secret")
+			l.Debug("debug test-secret")
+			l.Info("info test-secret")
+			l.TerminalError(bard.IdentifiableError{Name: "test-secret", Err: fmt.Errorf("error test-secret")})
+
+			Expect(b.String()).NotTo(ContainSubstring("test-secret"))
+			Expect(strings.Count(b.String(), bard.Redacted)).To(Equal(6))
+		})
+	})
+
+	context("structured", func() {
+		it.Before(func() {
+			l = bard.NewStructuredLogger(b)
+		})
+
+		it("does not configure debug", func() {
+			Expect(l.IsDebugEnabled()).To(BeFalse())
+		})
+
+		it("writes body log as JSON", func() {
+			l.Body("test-message")
+			Expect(b.String()).To(HavePrefix(`{"level":"info","phase":"body","message":"test-message","timestamp":`))
+		})
+
+		it("writes header log as JSON", func() {
+			l.Headerf("test-%s", "message")
+			Expect(b.String()).To(HavePrefix(`{"level":"info","phase":"header","message":"test-message","timestamp":`))
+		})
+
+		it("writes title log as JSON without color", func() {
+			l.Title(libcnb.Buildpack{
+				Info: libcnb.BuildpackInfo{
+					Name:     "test-name",
+					Version:  "test-version",
+					Homepage: "test-homepage",
+				},
+			})
+
+			lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HavePrefix(`{"level":"info","phase":"title","message":"test-name test-version","timestamp":`))
+			Expect(lines[1]).To(HavePrefix(`{"level":"info","phase":"header","message":"test-homepage","timestamp":`))
+		})
+
+		it("writes terminal error as JSON", func() {
+			l.TerminalError(bard.IdentifiableError{Name: "test-name", Description: "test-description", Err: fmt.Errorf("test-error")})
+
+			lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HavePrefix(`{"level":"error","phase":"terminal-error","message":"test-name test-description","timestamp":`))
+			Expect(lines[1]).To(HavePrefix(`{"level":"error","phase":"terminal-error","message":"test-error","timestamp":`))
+		})
+
+		context("with BP_DEBUG", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DEBUG", "")).To(Succeed())
+				l = bard.NewStructuredLogger(b)
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DEBUG")).To(Succeed())
+			})
+
+			it("writes debug log as JSON", func() {
+				l.Debug("test-message")
+				Expect(b.String()).To(HavePrefix(`{"level":"debug","phase":"debug","message":"test-message","timestamp":`))
+			})
+		})
+	})
+
 	context("with debug disabled", func() {
 		it.Before(func() {
 			l = bard.NewLoggerWithOptions(b)
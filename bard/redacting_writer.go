@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bard
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Redacted is the value that secrets are replaced with.
+const Redacted = "[REDACTED]"
+
+// RedactingWriter is an object that will mask a set of secrets in all output flowing through it.  A secret that is split
+// across writes is masked as well: output that ends with the beginning of a secret is held back until the next Write
+// shows whether it continues the secret, or until Flush is called.
+type RedactingWriter struct {
+	pending  string
+	replacer *strings.Replacer
+	secrets  []string
+	writer   io.Writer
+}
+
+// NewRedactingWriter creates an instance that wraps another writer.  Empty secrets are ignored.
+func NewRedactingWriter(writer io.Writer, secrets ...string) *RedactingWriter {
+	var s []string
+	for _, secret := range secrets {
+		if secret != "" {
+			s = append(s, secret)
+		}
+	}
+
+	// longest secrets first, so that a secret containing another is masked entirely
+	sort.SliceStable(s, func(i, j int) bool {
+		return len(s[i]) > len(s[j])
+	})
+
+	var pairs []string
+	for _, secret := range s {
+		pairs = append(pairs, secret, Redacted)
+	}
+
+	return &RedactingWriter{
+		replacer: strings.NewReplacer(pairs...),
+		secrets:  s,
+		writer:   writer,
+	}
+}
+
+func (r *RedactingWriter) Write(b []byte) (int, error) {
+	n := len(b)
+
+	out := r.replacer.Replace(r.pending + string(b))
+	held := r.partialSecret(out)
+	r.pending = out[len(out)-held:]
+
+	if _, err := io.WriteString(r.writer, out[:len(out)-held]); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// Flush writes any output that has been held back because it ends with the beginning of a secret.
+func (r *RedactingWriter) Flush() error {
+	pending := r.pending
+	r.pending = ""
+
+	if _, err := io.WriteString(r.writer, pending); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// partialSecret returns the length of the longest suffix of s that is the beginning, but not all, of a secret.
+func (r *RedactingWriter) partialSecret(s string) int {
+	longest := 0
+	for _, secret := range r.secrets {
+		for i := min(len(secret)-1, len(s)); i > longest; i-- {
+			if strings.HasPrefix(secret, s[len(s)-i:]) {
+				longest = i
+				break
+			}
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bard
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var sgrPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StructuredWriter is an object that will write all output flowing through it as JSON lines.  Each call to Write
+// results in a single line containing the level, phase, message, and timestamp.  Color codes are removed from the
+// message.
+type StructuredWriter struct {
+	level  string
+	phase  string
+	writer io.Writer
+}
+
+// NewStructuredWriter creates an instance that wraps another writer.
+func NewStructuredWriter(writer io.Writer, level string, phase string) *StructuredWriter {
+	return &StructuredWriter{
+		level:  level,
+		phase:  phase,
+		writer: writer,
+	}
+}
+
+type structuredEntry struct {
+	Level     string    `json:"level"`
+	Phase     string    `json:"phase"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (s *StructuredWriter) Write(b []byte) (int, error) {
+	n := len(b)
+
+	message := strings.TrimSpace(stripColor(string(b)))
+	if message == "" {
+		return n, nil
+	}
+
+	line, err := json.Marshal(structuredEntry{
+		Level:     s.level,
+		Phase:     s.phase,
+		Message:   message,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.writer.Write(append(line, '\n')); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func stripColor(s string) string {
+	return sgrPattern.ReplaceAllString(s, "")
+}
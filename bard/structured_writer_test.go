@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bard_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+func testStructuredWriter(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buffer *bytes.Buffer
+		writer *bard.StructuredWriter
+	)
+
+	it.Before(func() {
+		buffer = bytes.NewBuffer(nil)
+		writer = bard.NewStructuredWriter(buffer, "info", "body")
+	})
+
+	it("writes a JSON line", func() {
+		_, err := writer.Write([]byte("some-text\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var entry map[string]interface{}
+		Expect(json.Unmarshal(buffer.Bytes(), &entry)).To(Succeed())
+		Expect(entry["level"]).To(Equal("info"))
+		Expect(entry["phase"]).To(Equal("body"))
+		Expect(entry["message"]).To(Equal("some-text"))
+
+		timestamp, err := time.Parse(time.RFC3339Nano, entry["timestamp"].(string))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timestamp).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	it("removes color codes", func() {
+		_, err := writer.Write([]byte("\x1b[34msome-\x1b[1mtext\x1b[0m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(ContainSubstring(`"message":"some-text"`))
+	})
+
+	it("writes one line per write", func() {
+		_, err := writer.Write([]byte("some-text\nother-text\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(buffer.String(), "\n")).To(Equal(1))
+		Expect(buffer.String()).To(ContainSubstring(`"message":"some-text\nother-text"`))
+	})
+
+	it("skips empty writes", func() {
+		n, err := writer.Write([]byte("\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(buffer.String()).To(BeEmpty())
+	})
+}
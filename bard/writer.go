@@ -19,6 +19,7 @@ package bard
 import (
 	"bytes"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/heroku/color"
@@ -109,6 +110,49 @@ func (w *Writer) Write(b []byte) (int, error) {
 	return n, nil
 }
 
+// ColorStrippingWriter is an object that will remove all color codes from output flowing through it.
+type ColorStrippingWriter struct {
+	writer io.Writer
+}
+
+// NewColorStrippingWriter creates an instance that wraps another writer.
+func NewColorStrippingWriter(writer io.Writer) *ColorStrippingWriter {
+	return &ColorStrippingWriter{writer: writer}
+}
+
+func (c *ColorStrippingWriter) Write(b []byte) (int, error) {
+	n := len(b)
+
+	if _, err := c.writer.Write([]byte(stripColor(string(b)))); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// IsColorSupported indicates whether color codes should be written to a writer.  Color is not supported if either
+// $NO_COLOR or $CNB_NO_COLOR is set to a non-empty value, or if the writer is a file that is not a terminal.  Writers
+// that are not files are assumed to support color.
+func IsColorSupported(writer io.Writer) bool {
+	for _, name := range []string{"NO_COLOR", "CNB_NO_COLOR"} {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			return false
+		}
+	}
+
+	f, ok := writer.(*os.File)
+	if !ok {
+		return true
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // WriterOption is a function for configuring a Writer instance.
 type WriterOption func(Writer) Writer
 
@@ -18,6 +18,8 @@ package bard_test
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/heroku/color"
@@ -137,4 +139,65 @@ func testWriter(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("ColorStrippingWriter", func() {
+		var (
+			buffer *bytes.Buffer
+		)
+
+		it.Before(func() {
+			buffer = bytes.NewBuffer(nil)
+		})
+
+		it("removes color codes", func() {
+			writer := bard.NewWriter(bard.NewColorStrippingWriter(buffer), bard.WithAttributes(color.FgBlue), bard.WithIndent(1))
+
+			n, err := writer.Write([]byte("some-text\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(10))
+			Expect(buffer.String()).To(Equal("  some-text\n"))
+		})
+	})
+
+	context("IsColorSupported", func() {
+		it("supports color for non-file writers", func() {
+			Expect(bard.IsColorSupported(bytes.NewBuffer(nil))).To(BeTrue())
+		})
+
+		it("does not support color for regular files", func() {
+			f, err := os.Create(filepath.Join(t.TempDir(), "log"))
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			Expect(bard.IsColorSupported(f)).To(BeFalse())
+		})
+
+		context("$NO_COLOR", func() {
+			it.Before(func() {
+				Expect(os.Setenv("NO_COLOR", "1")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("NO_COLOR")).To(Succeed())
+			})
+
+			it("does not support color", func() {
+				Expect(bard.IsColorSupported(bytes.NewBuffer(nil))).To(BeFalse())
+			})
+		})
+
+		context("$CNB_NO_COLOR", func() {
+			it.Before(func() {
+				Expect(os.Setenv("CNB_NO_COLOR", "true")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("CNB_NO_COLOR")).To(Succeed())
+			})
+
+			it("does not support color", func() {
+				Expect(bard.IsColorSupported(bytes.NewBuffer(nil))).To(BeFalse())
+			})
+		})
+	})
 }
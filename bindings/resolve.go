@@ -77,6 +77,18 @@ func ResolveOne(binds libcnb.Bindings, predicates ...Predicate) (libcnb.Binding,
 	return resolved[0], true, nil
 }
 
+// Secrets returns the values of every secret in binds.  The result is suitable for passing to bard.WithSecrets so that
+// binding values are never written to the build output.
+func Secrets(binds libcnb.Bindings) []string {
+	var secrets []string
+	for _, bind := range binds {
+		for _, v := range bind.Secret {
+			secrets = append(secrets, v)
+		}
+	}
+	return secrets
+}
+
 func errTooManyBindings(binds libcnb.Bindings) error {
 	var names []string
 	for _, bind := range binds {
@@ -188,4 +188,16 @@ func testResolve(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+	context("Secrets", func() {
+		it("returns all secret values", func() {
+			binds[0].Secret = map[string]string{"username": "test-username"}
+			binds[2].Secret = map[string]string{"password": "test-password"}
+
+			Expect(bindings.Secrets(binds)).To(ConsistOf("test-username", "test-password"))
+		})
+
+		it("returns nothing without secrets", func() {
+			Expect(bindings.Secrets(binds)).To(BeEmpty())
+		})
+	})
 }
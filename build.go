@@ -17,6 +17,9 @@
 package libpak
 
 import (
+	"os"
+	"time"
+
 	"github.com/buildpacks/libcnb"
 
 	"github.com/paketo-buildpacks/libpak/bard"
@@ -34,6 +37,14 @@ func Build(builder libcnb.Builder, options ...libcnb.Option) {
 	)
 }
 
+// LayerContributionObserver is the interface implemented by a libcnb.Builder that wants to be notified of how long the
+// contribution of each of its layers took, for example to record metrics.
+type LayerContributionObserver interface {
+
+	// OnLayerContributed is called after the layer named name has been contributed, with the time taken.
+	OnLayerContributed(name string, duration time.Duration)
+}
+
 type buildDelegate struct {
 	delegate libcnb.Builder
 }
@@ -48,5 +59,36 @@ func (b buildDelegate) Build(context libcnb.BuildContext) (libcnb.BuildResult, e
 		}
 	}
 
+	observer, _ := b.delegate.(LayerContributionObserver)
+	logger := bard.NewLogger(os.Stdout)
+	for i, c := range result.Layers {
+		result.Layers[i] = timedLayerContributor{delegate: c, logger: logger, observer: observer}
+	}
+
 	return result, err
 }
+
+// timedLayerContributor times the contribution of a layer, logging the duration at debug level and notifying the
+// observer, if any.
+type timedLayerContributor struct {
+	delegate libcnb.LayerContributor
+	logger   bard.Logger
+	observer LayerContributionObserver
+}
+
+func (t timedLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	start := time.Now()
+	layer, err := t.delegate.Contribute(layer)
+	duration := time.Since(start)
+
+	t.logger.Debugf("Contribution of layer %s took %s", t.delegate.Name(), duration)
+	if t.observer != nil {
+		t.observer.OnLayerContributed(t.delegate.Name(), duration)
+	}
+
+	return layer, err
+}
+
+func (t timedLayerContributor) Name() string {
+	return t.delegate.Name()
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/buildpacks/libcnb"
 	"github.com/buildpacks/libcnb/mocks"
@@ -124,4 +125,59 @@ version = "test-version"`),
 			Err:         fmt.Errorf("test-error"),
 		}))
 	})
+
+	it("notifies the builder of the time taken to contribute each layer", func() {
+		Expect(os.WriteFile(filepath.Join(buildpackPath, "buildpack.toml"), []byte(`
+api = "0.6"
+
+[buildpack]
+name    = "test-name"
+version = "test-version"`),
+			0644)).To(Succeed())
+
+		b := &observingBuilder{}
+
+		libpak.Build(b,
+			libcnb.WithArguments([]string{commandPath, layersPath, platformPath, buildpackPlanPath}),
+			libcnb.WithEnvironmentWriter(environmentWriter),
+			libcnb.WithExitHandler(exitHandler),
+			libcnb.WithTOMLWriter(tomlWriter),
+		)
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(b.contributed).To(Equal([]string{"test-layer-1", "test-layer-2"}))
+		Expect(b.durations[0]).To(BeNumerically(">=", 10*time.Millisecond))
+	})
+}
+
+type observingBuilder struct {
+	contributed []string
+	durations   []time.Duration
+}
+
+func (o *observingBuilder) Build(libcnb.BuildContext) (libcnb.BuildResult, error) {
+	result := libcnb.NewBuildResult()
+	result.Layers = append(result.Layers,
+		sleepingLayerContributor{name: "test-layer-1", duration: 10 * time.Millisecond},
+		sleepingLayerContributor{name: "test-layer-2"})
+	return result, nil
+}
+
+func (o *observingBuilder) OnLayerContributed(name string, duration time.Duration) {
+	o.contributed = append(o.contributed, name)
+	o.durations = append(o.durations, duration)
+}
+
+type sleepingLayerContributor struct {
+	name     string
+	duration time.Duration
+}
+
+func (s sleepingLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	time.Sleep(s.duration)
+	return layer, nil
+}
+
+func (s sleepingLayerContributor) Name() string {
+	return s.name
 }
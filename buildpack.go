@@ -18,8 +18,11 @@ package libpak
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"sort"
@@ -27,6 +30,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/semver/v3"
 	"github.com/buildpacks/libcnb"
 	"github.com/heroku/color"
@@ -65,6 +69,53 @@ type BuildpackDependencyLicense struct {
 	URI string `toml:"uri"`
 }
 
+// spdxLicenseURIs maps well-known license URIs to their SPDX identifiers.
+var spdxLicenseURIs = map[string]string{
+	"apache.org/licenses/LICENSE-2.0":                    "Apache-2.0",
+	"apache.org/licenses/LICENSE-2.0.txt":                "Apache-2.0",
+	"eclipse.org/legal/epl-2.0":                          "EPL-2.0",
+	"gnu.org/licenses/gpl-2.0.html":                      "GPL-2.0-only",
+	"gnu.org/licenses/gpl-3.0.html":                      "GPL-3.0-only",
+	"gnu.org/software/classpath/license.html":            "GPL-2.0-only WITH Classpath-exception-2.0",
+	"openjdk.java.net/legal/gplv2+ce.html":               "GPL-2.0-only WITH Classpath-exception-2.0",
+	"openjdk.org/legal/gplv2+ce.html":                    "GPL-2.0-only WITH Classpath-exception-2.0",
+	"mozilla.org/en-US/MPL/2.0":                          "MPL-2.0",
+	"bouncycastle.org/licence.html":                      "MIT",
+	"golang.org/LICENSE":                                 "BSD-3-Clause",
+	"go.dev/LICENSE":                                     "BSD-3-Clause",
+	"raw.githubusercontent.com/nodejs/node/main/LICENSE": "MIT",
+}
+
+// SPDXExpression returns an SPDX license expression for the license.  The Type is used if set.  Otherwise, an SPDX
+// identifier is derived from the URI if it is a well-known license URI or an spdx.org or opensource.org license URI.
+// An empty string is returned if no expression can be determined.
+func (b BuildpackDependencyLicense) SPDXExpression() string {
+	if t := strings.TrimSpace(b.Type); t != "" {
+		return t
+	}
+
+	u, err := url.Parse(strings.TrimSpace(b.URI))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	p := strings.TrimSuffix(u.Path, "/")
+
+	if id, ok := spdxLicenseURIs[host+p]; ok {
+		return id
+	}
+
+	switch host {
+	case "spdx.org", "opensource.org":
+		if dir, file := path.Split(p); dir == "/licenses/" && file != "" {
+			return strings.TrimSuffix(strings.TrimSuffix(file, ".html"), ".php")
+		}
+	}
+
+	return ""
+}
+
 // BuildpackDependency describes a dependency known to the buildpack.
 type BuildpackDependency struct {
 	// ID is the dependency ID.
@@ -79,7 +130,8 @@ type BuildpackDependency struct {
 	// URI is the dependency URI.
 	URI string `toml:"uri"`
 
-	// SHA256 is the hash of the dependency.
+	// SHA256 is the hash of the dependency.  It is either a bare hex encoded SHA256 or, for other algorithms, in the form
+	// algorithm:hex where algorithm is one of sha256, sha384, or sha512.
 	SHA256 string `toml:"sha256"`
 
 	// Stacks are the stacks the dependency is compatible with.
@@ -96,6 +148,20 @@ type BuildpackDependency struct {
 
 	// DeprecationDate is the time when the dependency is deprecated
 	DeprecationDate time.Time `toml:"deprecation_date"`
+
+	// Source is the URI of the upstream source archive of the dependency.
+	Source string `toml:"source,omitempty"`
+
+	// SourceSHA256 is the hash of the upstream source archive of the dependency.
+	SourceSHA256 string `toml:"source-sha256,omitempty"`
+
+	// Filename is the optional name the artifact is stored under, in place of the last element of the URI.  It is
+	// useful when the URI does not end in a meaningful name, such as https://example.com/download?file=x.
+	Filename string `toml:"filename,omitempty"`
+
+	// Headers are optional headers to set on requests to download the dependency, such as a Referer or an API token
+	// required by a CDN.  Their values may be sensitive, so they are never written to metadata or logged.
+	Headers map[string]string `toml:"-"`
 }
 
 // Equals compares the 2 structs if they are equal. This is very simiar to reflect.DeepEqual
@@ -111,9 +177,19 @@ func (b1 BuildpackDependency) Equals(b2 BuildpackDependency) bool {
 		b2.CPEs = nil
 	}
 
+	// Headers are not persisted with the dependency, so they never match cached metadata.
+	b1.Headers = nil
+	b2.Headers = nil
+
 	return reflect.DeepEqual(b1, b2)
 }
 
+// EqualsIgnoringMetadata compares only the fields that identify the dependency artifact: ID, Version, URI, and SHA256.
+// Descriptive metadata such as DeprecationDate, Licenses, and CPEs is ignored.
+func (b1 BuildpackDependency) EqualsIgnoringMetadata(b2 BuildpackDependency) bool {
+	return b1.ID == b2.ID && b1.Version == b2.Version && b1.URI == b2.URI && b1.SHA256 == b2.SHA256
+}
+
 // AsBOMEntry renders a bill of materials entry describing the dependency.
 //
 // Deprecated: as of Buildpacks RFC 95, use `BuildpackDependency.AsSyftArtifact` instead
@@ -133,9 +209,17 @@ func (b BuildpackDependency) AsBOMEntry() libcnb.BOMEntry {
 
 // AsSyftArtifact renders a bill of materials entry describing the dependency as Syft.
 func (b BuildpackDependency) AsSyftArtifact() (sbom.SyftArtifact, error) {
+	return b.AsSyftArtifactFrom("buildpack.toml")
+}
+
+// AsSyftArtifactFrom renders a bill of materials entry describing the dependency as Syft, located in the given source
+// file.  This is typically buildpack.toml or extension.toml.
+func (b BuildpackDependency) AsSyftArtifactFrom(source string) (sbom.SyftArtifact, error) {
 	licenses := []string{}
 	for _, license := range b.Licenses {
-		licenses = append(licenses, license.Type)
+		if l := license.SPDXExpression(); l != "" {
+			licenses = append(licenses, l)
+		}
 	}
 
 	sbomArtifact := sbom.SyftArtifact{
@@ -144,7 +228,7 @@ func (b BuildpackDependency) AsSyftArtifact() (sbom.SyftArtifact, error) {
 		Type:      "UnknownPackage",
 		FoundBy:   "libpak",
 		Licenses:  licenses,
-		Locations: []sbom.SyftLocation{{Path: "buildpack.toml"}},
+		Locations: []sbom.SyftLocation{{Path: source}},
 		CPEs:      b.CPEs,
 		PURL:      b.PURL,
 	}
@@ -158,6 +242,45 @@ func (b BuildpackDependency) AsSyftArtifact() (sbom.SyftArtifact, error) {
 	return sbomArtifact, nil
 }
 
+// AsSourceSyftArtifactFrom renders a bill of materials entry describing the upstream source archive of the dependency
+// as Syft, located in the given source file.  If the dependency has a package URL, the source URI is added to it as the
+// download_url qualifier.  The boolean is false if the dependency has no Source.
+func (b BuildpackDependency) AsSourceSyftArtifactFrom(source string) (sbom.SyftArtifact, bool, error) {
+	if b.Source == "" {
+		return sbom.SyftArtifact{}, false, nil
+	}
+
+	sbomArtifact, err := b.AsSyftArtifactFrom(source)
+	if err != nil {
+		return sbom.SyftArtifact{}, false, err
+	}
+
+	sbomArtifact.Name = fmt.Sprintf("%s (source)", b.Name)
+	sbomArtifact.CPEs = nil
+
+	if b.PURL != "" {
+		separator := "?"
+		if strings.Contains(b.PURL, "?") {
+			separator = "&"
+		}
+		sbomArtifact.PURL = fmt.Sprintf("%s%s%s", b.PURL, separator, url.Values{"download_url": {b.Source}}.Encode())
+	}
+
+	sbomArtifact.ID = ""
+	sbomArtifact.ID, err = sbomArtifact.Hash()
+	if err != nil {
+		return sbom.SyftArtifact{}, false, fmt.Errorf("unable to generate hash\n%w", err)
+	}
+
+	return sbomArtifact, true, nil
+}
+
+// Arch returns the architecture of the dependency, taken from the arch qualifier of its PURL.  A dependency without a
+// PURL is amd64, and one whose PURL has no arch qualifier is the architecture of the system or $BP_ARCH.
+func (b BuildpackDependency) Arch() (string, error) {
+	return archFromPURL(b.PURL)
+}
+
 func (b BuildpackDependency) IsDeprecated() bool {
 	deprecationDate := b.DeprecationDate.UTC()
 	now := time.Now().UTC()
@@ -170,6 +293,19 @@ func (b BuildpackDependency) IsSoonDeprecated() bool {
 	return deprecationDate.Add(-30*24*time.Hour).Before(now) && deprecationDate.After(now)
 }
 
+// BuildpackTarget describes a platform that the buildpack supports.
+type BuildpackTarget struct {
+
+	// OS is the operating system of the target, for example linux.
+	OS string `toml:"os"`
+
+	// Arch is the architecture of the target, for example amd64 or arm64.
+	Arch string `toml:"arch"`
+
+	// Variant is the architecture variant of the target.  Optional.
+	Variant string `toml:"variant"`
+}
+
 // BuildpackMetadata is an extension to libcnb.Buildpack's metadata with opinions.
 type BuildpackMetadata struct {
 
@@ -185,6 +321,9 @@ type BuildpackMetadata struct {
 
 	// PrePackage describes a command to invoke before packaging.
 	PrePackage string
+
+	// VersionAliases maps names, for example lts, to the version constraints they stand for when resolving dependencies.
+	VersionAliases map[string]string
 }
 
 // NewBuildpackMetadata creates a new instance of BuildpackMetadata from the contents of libcnb.Buildpack.Metadata
@@ -275,6 +414,27 @@ func NewBuildpackMetadata(metadata map[string]interface{}) (BuildpackMetadata, e
 				d.PURL = v
 			}
 
+			if v, ok := v["source"].(string); ok {
+				d.Source = v
+			}
+
+			if v, ok := v["source-sha256"].(string); ok {
+				d.SourceSHA256 = v
+			}
+
+			if v, ok := v["filename"].(string); ok {
+				d.Filename = v
+			}
+
+			if v, ok := v["headers"].(map[string]interface{}); ok {
+				d.Headers = map[string]string{}
+				for k, v := range v {
+					if v, ok := v.(string); ok {
+						d.Headers[k] = v
+					}
+				}
+			}
+
 			if v, ok := v["deprecation_date"].(string); ok {
 				deprecationDate, err := time.Parse(time.RFC3339, v)
 
@@ -299,9 +459,82 @@ func NewBuildpackMetadata(metadata map[string]interface{}) (BuildpackMetadata, e
 		m.PrePackage = v
 	}
 
+	if v, ok := metadata["version-aliases"].(map[string]interface{}); ok {
+		m.VersionAliases = map[string]string{}
+		for k, v := range v {
+			if v, ok := v.(string); ok {
+				m.VersionAliases[k] = v
+			}
+		}
+	}
+
 	return m, nil
 }
 
+// NewBuildpackTargets returns the platforms declared in the top-level [[targets]] table of the buildpack.toml in the
+// buildpack's directory.  A buildpack without a buildpack.toml or without targets declares no targets.
+func NewBuildpackTargets(buildpack libcnb.Buildpack) ([]BuildpackTarget, error) {
+	if buildpack.Path == "" {
+		return nil, nil
+	}
+
+	file := filepath.Join(buildpack.Path, "buildpack.toml")
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	var raw struct {
+		Targets []BuildpackTarget `toml:"targets"`
+	}
+	if err := toml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("unable to decode targets in %s\n%w", file, err)
+	}
+
+	return raw.Targets, nil
+}
+
+// MergeBuildpackMetadata merges the Configurations and Dependencies of the metadata of several buildpacks, such as the
+// children of a composite buildpack, so that a single ConfigurationResolver can describe all of them.  Configurations
+// are de-duplicated by name, keeping the first, and it is an error for two configurations with the same name to have
+// different defaults.  Dependencies that are equal are only included once.  All other fields are left empty.
+func MergeBuildpackMetadata(metadata ...BuildpackMetadata) (BuildpackMetadata, error) {
+	var (
+		merged         BuildpackMetadata
+		configurations = map[string]BuildpackConfiguration{}
+	)
+
+	for _, m := range metadata {
+		for _, c := range m.Configurations {
+			if existing, ok := configurations[c.Name]; ok {
+				if existing.Default != c.Default {
+					return BuildpackMetadata{}, fmt.Errorf("conflicting defaults for configuration %s: %q and %q",
+						c.Name, existing.Default, c.Default)
+				}
+				continue
+			}
+
+			configurations[c.Name] = c
+			merged.Configurations = append(merged.Configurations, c)
+		}
+
+	dependencies:
+		for _, d := range m.Dependencies {
+			for _, existing := range merged.Dependencies {
+				if existing.Equals(d) {
+					continue dependencies
+				}
+			}
+
+			merged.Dependencies = append(merged.Dependencies, d)
+		}
+	}
+
+	return merged, nil
+}
+
 // ConfigurationResolver provides functionality for resolving a configuration value.
 type ConfigurationResolver struct {
 
@@ -437,6 +670,112 @@ func (c *ConfigurationResolver) Resolve(name string) (string, bool) {
 	return "", false
 }
 
+// libpakConfigurations are the environment variables read by libpak itself.
+var libpakConfigurations = []string{
+	"BP_ARCH",
+	"BP_BOM_LABEL_DISABLED",
+	"BP_DEBUG",
+	"BP_DEPENDENCY_CACHE_DIR",
+	"BP_DEPENDENCY_ID_MIRROR",
+	"BP_DEPENDENCY_MIRROR",
+	"BP_DIALER_KEEP_ALIVE",
+	"BP_DIALER_NETWORK",
+	"BP_DIALER_TIMEOUT",
+	"BP_EXPECT_CONTINUE_TIMEOUT",
+	"BP_INSECURE_LOCALHOST",
+	"BP_LOG_LEVEL",
+	"BP_MAX_ARTIFACT_SIZE",
+	"BP_RESPONSE_HEADER_TIMEOUT",
+	"BP_TLS_HANDSHAKE_TIMEOUT",
+}
+
+// libpakConfigurationPrefixes are the prefixes of the environment variables read by libpak itself, such as the
+// hostname and dependency id specific mirrors.
+var libpakConfigurationPrefixes = []string{
+	"BP_DEPENDENCY_ID_MIRROR_",
+	"BP_DEPENDENCY_MIRROR_",
+}
+
+// isLibpakConfiguration indicates whether name is one of the environment variables read by libpak itself.
+func isLibpakConfiguration(name string) bool {
+	for _, p := range libpakConfigurationPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WarnUnknown logs a warning for each $BP_ environment variable that is neither one of the Configurations nor one of the
+// known names, but is so similar to one of them that it is likely a typo.  Dissimilar variables are ignored as they
+// usually configure other buildpacks.
+func (c *ConfigurationResolver) WarnUnknown(logger bard.Logger, known ...string) {
+	names := map[string]bool{}
+	for _, n := range libpakConfigurations {
+		names[n] = true
+	}
+	for _, n := range known {
+		names[n] = true
+	}
+	for _, c := range c.Configurations {
+		names[c.Name] = true
+	}
+
+	var env []string
+	for _, e := range os.Environ() {
+		if name, _, _ := strings.Cut(e, "="); strings.HasPrefix(name, "BP_") && !names[name] && !isLibpakConfiguration(name) {
+			env = append(env, name)
+		}
+	}
+	sort.Strings(env)
+
+	var candidates []string
+	for n := range names {
+		candidates = append(candidates, n)
+	}
+	sort.Strings(candidates)
+
+	for _, e := range env {
+		suggestion, distance := "", 3
+		for _, n := range candidates {
+			if d := editDistance(e, n); d < distance {
+				suggestion, distance = n, d
+			}
+		}
+
+		if suggestion != "" {
+			logger.Headerf("%s $%s is not a known configuration, did you mean $%s?",
+				color.New(color.FgYellow, color.Bold).Sprint("Warning:"), e, suggestion)
+		}
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
 // ResolveBool resolves a boolean value for a configuration option. Returns true for 1, t, T, TRUE, true, True. Returns
 // false for all other values or unset.
 func (c *ConfigurationResolver) ResolveBool(name string) bool {
@@ -449,6 +788,20 @@ func (c *ConfigurationResolver) ResolveBool(name string) bool {
 	return t
 }
 
+// ResolveBoolLenient resolves a boolean value for a configuration option, also accepting the synonyms commonly used by
+// people.  Returns true for 1, t, true, y, yes, and on, ignoring case and surrounding whitespace.  Returns false for all
+// other values or unset.  Unlike ResolveBool, BP_EXAMPLE=yes is true.
+func (c *ConfigurationResolver) ResolveBoolLenient(name string) bool {
+	s, _ := c.Resolve(name)
+
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
 // DependencyResolver provides functionality for resolving a dependency given a collection of constraints.
 type DependencyResolver struct {
 
@@ -458,6 +811,17 @@ type DependencyResolver struct {
 	// StackID is the stack id of the build.
 	StackID string
 
+	// VersionAliases maps names to the version constraints they stand for.
+	VersionAliases map[string]string
+
+	// Targets are the platforms that the buildpack declares support for.  If set, a resolved dependency's architecture
+	// must match the architecture of one of the targets.
+	Targets []BuildpackTarget
+
+	// Availability optionally reports whether a candidate dependency can be used, for example whether its artifact can
+	// still be downloaded.  An unavailable candidate is skipped in favour of the next highest version.
+	Availability func(dependency BuildpackDependency) bool
+
 	// Logger is the logger used to write to the console.
 	Logger *bard.Logger
 }
@@ -469,7 +833,17 @@ func NewDependencyResolver(context libcnb.BuildContext) (DependencyResolver, err
 		return DependencyResolver{}, fmt.Errorf("unable to unmarshal buildpack metadata\n%w", err)
 	}
 
-	return DependencyResolver{Dependencies: md.Dependencies, StackID: context.StackID}, nil
+	targets, err := NewBuildpackTargets(context.Buildpack)
+	if err != nil {
+		return DependencyResolver{}, fmt.Errorf("unable to read buildpack targets\n%w", err)
+	}
+
+	return DependencyResolver{
+		Dependencies:   md.Dependencies,
+		StackID:        context.StackID,
+		Targets:        targets,
+		VersionAliases: md.VersionAliases,
+	}, nil
 }
 
 // NoValidDependenciesError is returned when the resolver cannot find any valid dependencies given the constraints.
@@ -490,21 +864,27 @@ func IsNoValidDependencies(err error) bool {
 
 // Resolve returns the latest version of a dependency within the collection of Dependencies.  The candidate set is first
 // filtered by the constraints, then the remaining candidates are sorted for the latest result by semver semantics.
-// Version can contain wildcards and defaults to "*" if not specified.
+// Version can contain wildcards and defaults to "*" if not specified.  Version may also be "latest", meaning the highest
+// stable version, or one of the VersionAliases.  Of candidates with the same version, one that lists the StackID is
+// preferred over one that only matches through the wildcard stack.
 func (d *DependencyResolver) Resolve(id string, version string) (BuildpackDependency, error) {
 	if version == "" {
 		version = "*"
+	} else if v, ok := d.VersionAliases[version]; ok {
+		version = v
 	}
 
-	vc, err := semver.NewConstraint(version)
+	vm, err := NewVersionConstraintMatcher(version)
 	if err != nil {
-		return BuildpackDependency{}, fmt.Errorf("invalid constraint %s\n%w", vc, err)
+		return BuildpackDependency{}, fmt.Errorf("invalid constraint %s, it is neither a version constraint nor a known alias\n%w", version, err)
 	}
 
-	var candidates []BuildpackDependency
+	var (
+		candidates []BuildpackDependency
+		available  []*semver.Version
+	)
 	for _, c := range d.Dependencies {
-		v, err := semver.NewVersion(c.Version)
-		if err != nil {
+		if _, err := semver.NewVersion(c.Version); err != nil {
 			return BuildpackDependency{}, fmt.Errorf("unable to parse version %s\n%w", c.Version, err)
 		}
 
@@ -517,26 +897,53 @@ func (d *DependencyResolver) Resolve(id string, version string) (BuildpackDepend
 			continue
 		}
 
-		if c.ID == id && vc.Check(v) && d.contains(c.Stacks, d.StackID) {
+		if c.ID != id || !d.contains(c.Stacks, d.StackID) {
+			continue
+		}
+
+		v, _ := semver.NewVersion(c.Version)
+		available = append(available, v)
+
+		if vm.Matches(c.Version) {
 			candidates = append(candidates, c)
 		}
 	}
 
 	if len(candidates) == 0 {
-		return BuildpackDependency{}, NoValidDependenciesError{
-			Message: fmt.Sprintf("no valid dependencies for %s, %s, and %s in %s",
-				id, version, d.StackID, DependenciesFormatter(d.Dependencies)),
+		message := fmt.Sprintf("no valid dependencies for %s, %s, and %s in %s",
+			id, version, d.StackID, DependenciesFormatter(d.Dependencies))
+
+		if nearest := nearestVersions(version, available); len(nearest) > 0 {
+			message = fmt.Sprintf("%s, available: %s", message, strings.Join(nearest, ", "))
 		}
+
+		return BuildpackDependency{}, NoValidDependenciesError{Message: message}
 	}
 
-	sort.Slice(candidates, func(i int, j int) bool {
+	// among candidates of the same version, prefer one declared for the stack over the wildcard stack, and otherwise
+	// keep the order the dependencies are declared in
+	sort.SliceStable(candidates, func(i int, j int) bool {
 		a, _ := semver.NewVersion(candidates[i].Version)
 		b, _ := semver.NewVersion(candidates[j].Version)
 
+		if a.Equal(b) {
+			return d.matchesStack(candidates[i].Stacks) && !d.matchesStack(candidates[j].Stacks)
+		}
+
 		return a.GreaterThan(b)
 	})
 
-	candidate := candidates[0]
+	candidate, ok := d.firstAvailable(candidates)
+	if !ok {
+		return BuildpackDependency{}, NoValidDependenciesError{
+			Message: fmt.Sprintf("no available dependencies for %s, %s, and %s in %s",
+				id, version, d.StackID, DependenciesFormatter(candidates)),
+		}
+	}
+
+	if err := d.checkTarget(candidate); err != nil {
+		return BuildpackDependency{}, err
+	}
 
 	if (candidate.DeprecationDate != time.Time{}) {
 		d.printDependencyDeprecation(candidate)
@@ -545,6 +952,123 @@ func (d *DependencyResolver) Resolve(id string, version string) (BuildpackDepend
 	return candidate, nil
 }
 
+// firstAvailable returns the first of the candidates that is available.
+func (d *DependencyResolver) firstAvailable(candidates []BuildpackDependency) (BuildpackDependency, bool) {
+	if d.Availability == nil {
+		return candidates[0], true
+	}
+
+	for _, c := range candidates {
+		if d.Availability(c) {
+			return c, true
+		}
+
+		if d.Logger != nil {
+			d.Logger.Bodyf("%s %s %s is unavailable, trying the next version", color.YellowString("Skipping"), c.Name, c.Version)
+		}
+	}
+
+	return BuildpackDependency{}, false
+}
+
+// UnavailableVersions returns an Availability function for a DependencyResolver that reports the given versions of the
+// dependency with id as unavailable, for example releases that have been withdrawn upstream.
+func UnavailableVersions(id string, versions ...string) func(dependency BuildpackDependency) bool {
+	return func(dependency BuildpackDependency) bool {
+		if dependency.ID != id {
+			return true
+		}
+
+		for _, v := range versions {
+			if dependency.Version == v {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// HTTPAvailability returns an Availability function for a DependencyResolver that sends a HEAD request to the URI of
+// each candidate, reporting it as unavailable if the request fails or the response is not successful.  Candidates
+// with URIs that are not http or https are always available.  If client is nil, a client with a 10 second timeout is
+// used.
+func HTTPAvailability(client *http.Client) func(dependency BuildpackDependency) bool {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return func(dependency BuildpackDependency) bool {
+		u, err := url.Parse(dependency.URI)
+		if err != nil {
+			return false
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return true
+		}
+
+		resp, err := client.Head(dependency.URI)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+
+		return resp.StatusCode >= 200 && resp.StatusCode <= 299
+	}
+}
+
+// nearestVersions returns up to two available versions on either side of the requested version, in ascending order.
+// If the requested version is a constraint rather than a concrete version, the highest available versions are returned.
+func nearestVersions(requested string, available []*semver.Version) []string {
+	const limit = 2
+
+	sort.Sort(semver.Collection(available))
+
+	i := len(available)
+	if r, err := semver.NewVersion(requested); err == nil {
+		i = sort.Search(len(available), func(j int) bool { return !available[j].LessThan(r) })
+	}
+
+	lower, upper := i-limit, i+limit
+	if lower < 0 {
+		lower = 0
+	}
+	if upper > len(available) {
+		upper = len(available)
+	}
+
+	var nearest []string
+	for _, v := range available[lower:upper] {
+		if len(nearest) > 0 && nearest[len(nearest)-1] == v.Original() {
+			continue
+		}
+		nearest = append(nearest, v.Original())
+	}
+
+	return nearest
+}
+
+// checkTarget returns an error if Targets are declared and none of them match the architecture of the dependency.
+func (d *DependencyResolver) checkTarget(dependency BuildpackDependency) error {
+	if len(d.Targets) == 0 {
+		return nil
+	}
+
+	arch, err := archFromPURL(dependency.PURL)
+	if err != nil {
+		return fmt.Errorf("unable to compare arch\n%w", err)
+	}
+
+	for _, t := range d.Targets {
+		if t.Arch == arch {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("dependency %s %s has arch %s which does not match any declared target", dependency.ID, dependency.Version, arch)
+}
+
 func archFromPURL(rawPURL string) (string, error) {
 	if len(strings.TrimSpace(rawPURL)) == 0 {
 		return "amd64", nil
@@ -572,6 +1096,17 @@ func archFromSystem() string {
 	return archFromEnv
 }
 
+// matchesStack indicates whether stacks contains the StackID itself, rather than only the wildcard stack.
+func (d *DependencyResolver) matchesStack(stacks []string) bool {
+	for _, s := range stacks {
+		if s == d.StackID {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (DependencyResolver) contains(candidates []string, value string) bool {
 	if len(candidates) == 0 {
 		return true
@@ -19,32 +19,88 @@ package libpak
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/buildpacks/libcnb"
 	"github.com/imdario/mergo"
 )
 
+// PriorityKey is the metadata key of a BuildpackPlanEntry that declares its priority.  Entries without a priority have
+// a priority of 0.
+const PriorityKey = "priority"
+
 // PlanEntryResolver provides functionality for resolving a Buildpack Plan Entry given a name.
 type PlanEntryResolver struct {
 
 	// Plan is the BuildpackPlan to resolve against.
 	Plan libcnb.BuildpackPlan
+
+	// Strategy is the MergeStrategy used by Resolve to combine differing versions.  Defaults to CommaJoin.
+	Strategy MergeStrategy
 }
 
+// VersionKey is the metadata key of a BuildpackPlanEntry that declares its version.
+const VersionKey = "version"
+
+// MergeStrategy describes how ShallowMergeWith combines the versions of two BuildpackPlanEntry's when they differ.
+type MergeStrategy string
+
+const (
+	// CommaJoin combines differing versions with a comma delimiter.
+	CommaJoin MergeStrategy = "comma-join"
+
+	// HighestSemver keeps the higher of two versions by semver semantics.
+	HighestSemver MergeStrategy = "highest-semver"
+
+	// KeepA keeps the version of a.
+	KeepA MergeStrategy = "keep-a"
+
+	// KeepB keeps the version of b.
+	KeepB MergeStrategy = "keep-b"
+)
+
 // MergeFunc takes two BuildpackPlanEntry's and returns a merged entry.
 type MergeFunc func(a, b libcnb.BuildpackPlanEntry) (libcnb.BuildpackPlanEntry, error)
 
 // ResolveWithMerge returns a single BuildpackPlanEntry that is a merged version of all entries that have a given name.
-// A merge function is used to describe how two entries are merged together.
+// A merge function is used to describe how two entries are merged together.  Entries are merged in ascending order of
+// their PriorityKey metadata, so that a higher priority entry is always passed as b.  Entries with the same priority
+// are merged in the order they appear in the plan.
 func (p *PlanEntryResolver) ResolveWithMerge(name string, f MergeFunc) (libcnb.BuildpackPlanEntry, bool, error) {
-	m := libcnb.BuildpackPlanEntry{}
+	var (
+		entries    []libcnb.BuildpackPlanEntry
+		priorities []int64
+	)
 
-	var err error
 	for _, e := range p.Plan.Entries {
 		if e.Name == name {
-			if m, err = f(m, e); err != nil {
-				return libcnb.BuildpackPlanEntry{}, false, fmt.Errorf("error merging %+v and %+v\n%w", m, e, err)
+			priority, err := planEntryPriority(e)
+			if err != nil {
+				return libcnb.BuildpackPlanEntry{}, false, err
 			}
+
+			entries = append(entries, e)
+			priorities = append(priorities, priority)
+		}
+	}
+
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return priorities[order[i]] < priorities[order[j]]
+	})
+
+	m := libcnb.BuildpackPlanEntry{}
+
+	var err error
+	for _, i := range order {
+		e := entries[i]
+		if m, err = f(m, e); err != nil {
+			return libcnb.BuildpackPlanEntry{}, false, fmt.Errorf("error merging %+v and %+v\n%w", m, e, err)
 		}
 	}
 
@@ -58,14 +114,104 @@ func (p *PlanEntryResolver) ResolveWithMerge(name string, f MergeFunc) (libcnb.B
 // ShallowMerge merges two BuildpackPlanEntry's together.  Declared versions are combined with a comma delimiter and
 // metadata is combined with the values for b taking priority over the values of a when the keys are duplicated.
 func ShallowMerge(a, b libcnb.BuildpackPlanEntry) (libcnb.BuildpackPlanEntry, error) {
-	if err := mergo.Merge(&b, a); err != nil {
-		return libcnb.BuildpackPlanEntry{}, fmt.Errorf("unable to merge %+v and %+v\n%w", a, b, err)
-	}
+	return ShallowMergeWith(CommaJoin)(a, b)
+}
 
-	return b, nil
+// ShallowMergeWith returns a MergeFunc that merges two BuildpackPlanEntry's together.  Declared versions are combined
+// using strategy and metadata is combined with the values for b taking priority over the values of a when the keys are
+// duplicated.
+func ShallowMergeWith(strategy MergeStrategy) MergeFunc {
+	return func(a, b libcnb.BuildpackPlanEntry) (libcnb.BuildpackPlanEntry, error) {
+		version, err := mergeVersions(strategy, a, b)
+		if err != nil {
+			return libcnb.BuildpackPlanEntry{}, fmt.Errorf("unable to merge versions of %+v and %+v\n%w", a, b, err)
+		}
+
+		if err := mergo.Merge(&b, a); err != nil {
+			return libcnb.BuildpackPlanEntry{}, fmt.Errorf("unable to merge %+v and %+v\n%w", a, b, err)
+		}
+
+		if version != "" {
+			m := make(map[string]interface{}, len(b.Metadata))
+			for k, v := range b.Metadata {
+				m[k] = v
+			}
+			m[VersionKey] = version
+			b.Metadata = m
+		}
+
+		return b, nil
+	}
 }
 
-// Resolve calls ResolveWithMerge function passing in the ShallowMerge function as the merge strategy.
+// Resolve calls ResolveWithMerge function passing in the ShallowMergeWith function, configured with Strategy, as the
+// merge strategy.
 func (p *PlanEntryResolver) Resolve(name string) (libcnb.BuildpackPlanEntry, bool, error) {
-	return p.ResolveWithMerge(name, ShallowMerge)
+	strategy := p.Strategy
+	if strategy == "" {
+		strategy = CommaJoin
+	}
+
+	return p.ResolveWithMerge(name, ShallowMergeWith(strategy))
+}
+
+func mergeVersions(strategy MergeStrategy, a, b libcnb.BuildpackPlanEntry) (string, error) {
+	va, _ := a.Metadata[VersionKey].(string)
+	vb, _ := b.Metadata[VersionKey].(string)
+
+	if va == "" || va == vb {
+		return vb, nil
+	} else if vb == "" {
+		return va, nil
+	}
+
+	switch strategy {
+	case CommaJoin:
+		return fmt.Sprintf("%s,%s", va, vb), nil
+	case KeepA:
+		return va, nil
+	case KeepB:
+		return vb, nil
+	case HighestSemver:
+		sa, err := semver.NewVersion(va)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse version %s\n%w", va, err)
+		}
+
+		sb, err := semver.NewVersion(vb)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse version %s\n%w", vb, err)
+		}
+
+		if sa.GreaterThan(sb) {
+			return va, nil
+		}
+		return vb, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %s", strategy)
+	}
+}
+
+func planEntryPriority(entry libcnb.BuildpackPlanEntry) (int64, error) {
+	v, ok := entry.Metadata[PriorityKey]
+	if !ok {
+		return 0, nil
+	}
+
+	switch p := v.(type) {
+	case int:
+		return int64(p), nil
+	case int64:
+		return p, nil
+	case float64:
+		return int64(p), nil
+	case string:
+		i, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse priority %q of %s\n%w", p, entry.Name, err)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("unable to parse priority %v of %s: unsupported type %T", v, entry.Name, v)
+	}
 }
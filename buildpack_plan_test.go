@@ -112,6 +112,66 @@ func testBuildpackPlan(t *testing.T, context spec.G, it spec.S) {
 
 	})
 
+	context("ShallowMergeWith", func() {
+		var (
+			a = libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.10.0"},
+			}
+			b = libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.9.0"},
+			}
+		)
+
+		it("comma joins versions", func() {
+			Expect(libpak.ShallowMergeWith(libpak.CommaJoin)(a, b)).To(Equal(libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.10.0,1.9.0"},
+			}))
+		})
+
+		it("keeps a version", func() {
+			Expect(libpak.ShallowMergeWith(libpak.KeepA)(a, b)).To(Equal(libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.10.0"},
+			}))
+		})
+
+		it("keeps b version", func() {
+			Expect(libpak.ShallowMergeWith(libpak.KeepB)(a, b)).To(Equal(libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.9.0"},
+			}))
+		})
+
+		it("keeps highest semver version", func() {
+			Expect(libpak.ShallowMergeWith(libpak.HighestSemver)(a, b)).To(Equal(libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.10.0"},
+			}))
+		})
+
+		it("returns error with invalid semver version", func() {
+			c := libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "test-version"},
+			}
+
+			_, err := libpak.ShallowMergeWith(libpak.HighestSemver)(a, c)
+			Expect(err).To(MatchError(ContainSubstring("unable to parse version test-version")))
+		})
+
+		it("keeps single version", func() {
+			c := libcnb.BuildpackPlanEntry{Name: "test-name"}
+
+			Expect(libpak.ShallowMergeWith(libpak.KeepB)(a, c)).To(Equal(libcnb.BuildpackPlanEntry{
+				Name:     "test-name",
+				Metadata: map[string]interface{}{"version": "1.10.0"},
+			}))
+		})
+	})
+
 	context("PlanEntryResolver", func() {
 
 		context("ResolveWithMerge", func() {
@@ -162,10 +222,67 @@ func testBuildpackPlan(t *testing.T, context spec.G, it spec.S) {
 					Name: "test-name-2",
 				}))
 			})
+
+			context("priority", func() {
+				it.Before(func() {
+					resolver.Plan = libcnb.BuildpackPlan{
+						Entries: []libcnb.BuildpackPlanEntry{
+							{
+								Name:     "test-name",
+								Metadata: map[string]interface{}{"id": "test-1", "priority": int64(10)},
+							},
+							{
+								Name:     "test-name",
+								Metadata: map[string]interface{}{"id": "test-2"},
+							},
+							{
+								Name:     "test-name",
+								Metadata: map[string]interface{}{"id": "test-3", "priority": "-1"},
+							},
+						},
+					}
+				})
+
+				it("merges in order of priority", func() {
+					var ids []interface{}
+					g := func(a, b libcnb.BuildpackPlanEntry) (libcnb.BuildpackPlanEntry, error) {
+						ids = append(ids, b.Metadata["id"])
+						return b, nil
+					}
+
+					e, ok, err := resolver.ResolveWithMerge("test-name", g)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ok).To(BeTrue())
+					Expect(e.Metadata["id"]).To(Equal("test-1"))
+					Expect(ids).To(Equal([]interface{}{"test-3", "test-2", "test-1"}))
+				})
+
+				it("returns error with invalid priority", func() {
+					resolver.Plan.Entries[1].Metadata["priority"] = "test-priority"
+
+					_, _, err := resolver.ResolveWithMerge("test-name", f)
+					Expect(err).To(MatchError(ContainSubstring("unable to parse priority")))
+				})
+			})
 		})
 
 		context("Resolve", func() {
 
+			it("uses strategy", func() {
+				resolver := libpak.PlanEntryResolver{
+					Plan: libcnb.BuildpackPlan{Entries: []libcnb.BuildpackPlanEntry{
+						{Name: "test-name", Metadata: map[string]interface{}{"version": "1.10.0"}},
+						{Name: "test-name", Metadata: map[string]interface{}{"version": "1.9.0"}},
+					}},
+					Strategy: libpak.HighestSemver,
+				}
+
+				e, ok, err := resolver.Resolve("test-name")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(e.Metadata["version"]).To(Equal("1.10.0"))
+			})
+
 			it("merges with empty", func() {
 				a := libcnb.BuildpackPlanEntry{}
 				b := libcnb.BuildpackPlanEntry{Name: "test-name"}
@@ -271,6 +388,30 @@ func testBuildpackPlan(t *testing.T, context spec.G, it spec.S) {
 					Expect(ok).To(BeTrue())
 					Expect(e).To(Equal(expected))
 				})
+
+				it("keeps higher priority keys", func() {
+					a := libcnb.BuildpackPlanEntry{
+						Name:     "test-name",
+						Metadata: map[string]interface{}{"test-key-1": "test-value-1", "priority": int64(1)},
+					}
+					b := libcnb.BuildpackPlanEntry{
+						Name:     "test-name",
+						Metadata: map[string]interface{}{"test-key-1": "test-value-2"},
+					}
+
+					resolver := libpak.PlanEntryResolver{
+						Plan: libcnb.BuildpackPlan{Entries: []libcnb.BuildpackPlanEntry{a, b}},
+					}
+					expected := libcnb.BuildpackPlanEntry{
+						Name:     "test-name",
+						Metadata: map[string]interface{}{"test-key-1": "test-value-1", "priority": int64(1)},
+					}
+
+					e, ok, err := resolver.Resolve("test-name")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ok).To(BeTrue())
+					Expect(e).To(Equal(expected))
+				})
 			})
 		})
 	})
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+// BuildpackSBOMArtifact returns a Syft artifact describing the buildpack itself, so that the buildpack's own ID,
+// version, and licenses appear in scans of the image.
+func BuildpackSBOMArtifact(buildpack libcnb.Buildpack) (sbom.SyftArtifact, error) {
+	licenses := []string{}
+	for _, license := range buildpack.Info.Licenses {
+		licenses = append(licenses, license.Type)
+	}
+
+	artifact := sbom.SyftArtifact{
+		Name:      buildpack.Info.ID,
+		Version:   buildpack.Info.Version,
+		Type:      "UnknownPackage",
+		FoundBy:   "libpak",
+		Licenses:  licenses,
+		Locations: []sbom.SyftLocation{{Path: filepath.Join(buildpack.Path, "buildpack.toml")}},
+		CPEs:      []string{buildpackCPE(buildpack.Info, "buildpack")},
+		PURL:      buildpackPURL(buildpack.Info),
+	}
+	var err error
+	artifact.ID, err = artifact.Hash()
+	if err != nil {
+		return sbom.SyftArtifact{}, fmt.Errorf("unable to generate hash\n%w", err)
+	}
+
+	return artifact, nil
+}
+
+// BuildpackSBOMContributor writes a build SBOM describing the buildpack itself.
+type BuildpackSBOMContributor struct {
+
+	// Buildpack is the buildpack to describe.
+	Buildpack libcnb.Buildpack
+
+	// Logger is the logger to use.
+	Logger bard.Logger
+}
+
+// NewBuildpackSBOMContributor returns a new BuildpackSBOMContributor for the buildpack.
+func NewBuildpackSBOMContributor(buildpack libcnb.Buildpack, logger bard.Logger) BuildpackSBOMContributor {
+	return BuildpackSBOMContributor{Buildpack: buildpack, Logger: logger}
+}
+
+// Contribute adds the buildpack to the Syft build SBOM in layers, retaining any artifacts already in it.
+func (b BuildpackSBOMContributor) Contribute(layers libcnb.Layers) error {
+	artifact, err := BuildpackSBOMArtifact(b.Buildpack)
+	if err != nil {
+		return fmt.Errorf("unable to get SBOM artifact for buildpack\n%w", err)
+	}
+
+	path := layers.BuildSBOMPath(libcnb.SyftJSON)
+	b.Logger.Debugf("Writing Syft SBOM at %s: %+v", path, artifact)
+	if err := sbom.MergeSyftDependencies(path, sbom.NewSyftDependency(b.Buildpack.Path, []sbom.SyftArtifact{artifact})); err != nil {
+		return fmt.Errorf("unable to write SBOM\n%w", err)
+	}
+
+	return nil
+}
+
+// buildpackCPE returns the CPE of a named component of a buildpack.
+func buildpackCPE(info libcnb.BuildpackInfo, name string) string {
+	return fmt.Sprintf("cpe:2.3:a:%s:%s:%s:*:*:*:*:*:*:*", info.ID, name, info.Version)
+}
+
+// buildpackPURL returns the PURL of a buildpack.
+func buildpackPURL(info libcnb.BuildpackInfo) string {
+	return fmt.Sprintf("pkg:generic/%s@%s", info.ID, info.Version)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+func testBuildpackSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buildpack libcnb.Buildpack
+		layers    libcnb.Layers
+	)
+
+	it.Before(func() {
+		buildpack = libcnb.Buildpack{
+			Path: t.TempDir(),
+			Info: libcnb.BuildpackInfo{
+				ID:       "test-id",
+				Name:     "test-name",
+				Version:  "test-version",
+				Licenses: []libcnb.License{{Type: "Apache-2.0"}},
+			},
+		}
+
+		layers = libcnb.Layers{Path: t.TempDir()}
+	})
+
+	it("describes the buildpack", func() {
+		artifact, err := libpak.BuildpackSBOMArtifact(buildpack)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(artifact.Name).To(Equal("test-id"))
+		Expect(artifact.Version).To(Equal("test-version"))
+		Expect(artifact.Licenses).To(Equal([]string{"Apache-2.0"}))
+		Expect(artifact.Locations).To(Equal([]sbom.SyftLocation{{Path: filepath.Join(buildpack.Path, "buildpack.toml")}}))
+		Expect(artifact.CPEs).To(Equal([]string{"cpe:2.3:a:test-id:buildpack:test-version:*:*:*:*:*:*:*"}))
+		Expect(artifact.PURL).To(Equal("pkg:generic/test-id@test-version"))
+		Expect(artifact.ID).NotTo(BeEmpty())
+	})
+
+	it("adds the buildpack to the build SBOM", func() {
+		existing := sbom.NewSyftDependency(layers.Path, []sbom.SyftArtifact{{ID: "existing-id", Name: "existing"}})
+		Expect(existing.WriteTo(layers.BuildSBOMPath(libcnb.SyftJSON))).To(Succeed())
+
+		Expect(libpak.NewBuildpackSBOMContributor(buildpack, bard.NewLogger(os.Stdout)).Contribute(layers)).To(Succeed())
+
+		b, err := os.ReadFile(layers.BuildSBOMPath(libcnb.SyftJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		var dep sbom.SyftDependency
+		Expect(json.Unmarshal(b, &dep)).To(Succeed())
+		Expect(dep.Artifacts).To(HaveLen(2))
+		Expect(dep.Artifacts[0].Name).To(Equal("existing"))
+		Expect(dep.Artifacts[1].Name).To(Equal("test-id"))
+	})
+}
@@ -19,13 +19,16 @@ package libpak_test
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/libcnb"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 
 	"github.com/sclevine/spec"
 
@@ -67,6 +70,14 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 		Expect(dependency.Equals(newDependency)).To(BeTrue())
 	})
 
+	it("ignores headers when comparing dependencies", func() {
+		dependency := libpak.BuildpackDependency{ID: "test-id", Version: "1.1.1"}
+		other := dependency
+		other.Headers = map[string]string{"Referer": "test-referer"}
+
+		Expect(dependency.Equals(other)).To(BeTrue())
+	})
+
 	it("renders dependency as a BOMEntry", func() {
 		dependency := libpak.BuildpackDependency{
 			ID:      "test-id",
@@ -127,6 +138,92 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 		}))
 	})
 
+	it("renders dependency as a SyftArtifact from extension.toml", func() {
+		dependency := libpak.BuildpackDependency{
+			ID:      "test-id",
+			Name:    "test-name",
+			Version: "1.1.1",
+		}
+
+		a, err := dependency.AsSyftArtifactFrom("extension.toml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Locations).To(Equal([]sbom.SyftLocation{{Path: "extension.toml"}}))
+	})
+
+	it("compares dependencies ignoring metadata", func() {
+		dependency := libpak.BuildpackDependency{
+			ID:      "test-id",
+			Name:    "test-name",
+			Version: "1.1.1",
+			URI:     "test-uri",
+			SHA256:  "test-sha256",
+		}
+
+		other := dependency
+		other.Name = "other-name"
+		other.DeprecationDate = time.Now()
+		other.CPEs = []string{"test-cpe"}
+		Expect(dependency.EqualsIgnoringMetadata(other)).To(BeTrue())
+
+		other.SHA256 = "other-sha256"
+		Expect(dependency.EqualsIgnoringMetadata(other)).To(BeFalse())
+	})
+
+	it("returns the arch of the dependency", func() {
+		Expect(libpak.BuildpackDependency{PURL: "pkg:generic/test@1.1.1?arch=arm64"}.Arch()).To(Equal("arm64"))
+		Expect(libpak.BuildpackDependency{}.Arch()).To(Equal("amd64"))
+	})
+
+	it("renders dependency source as a SyftArtifact", func() {
+		dependency := libpak.BuildpackDependency{
+			ID:           "test-id",
+			Name:         "test-name",
+			Version:      "1.1.1",
+			CPEs:         []string{"test-cpe1"},
+			PURL:         "pkg:generic/test-name@1.1.1",
+			Source:       "https://example.com/test-source.tar.gz",
+			SourceSHA256: "test-source-sha256",
+		}
+
+		a, ok, err := dependency.AsSourceSyftArtifactFrom("buildpack.toml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(a.Name).To(Equal("test-name (source)"))
+		Expect(a.CPEs).To(BeNil())
+		Expect(a.PURL).To(Equal("pkg:generic/test-name@1.1.1?download_url=https%3A%2F%2Fexample.com%2Ftest-source.tar.gz"))
+		Expect(a.ID).NotTo(BeEmpty())
+
+		_, ok, err = libpak.BuildpackDependency{ID: "test-id"}.AsSourceSyftArtifactFrom("buildpack.toml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	it("derives SyftArtifact licenses from uri when type is empty", func() {
+		dependency := libpak.BuildpackDependency{
+			ID:      "test-id",
+			Name:    "test-name",
+			Version: "1.1.1",
+			Licenses: []libpak.BuildpackDependencyLicense{
+				{URI: "https://www.apache.org/licenses/LICENSE-2.0"},
+				{URI: "https://opensource.org/licenses/MIT"},
+				{URI: "https://spdx.org/licenses/BSD-3-Clause.html"},
+				{URI: "https://example.com/license"},
+				{Type: " "},
+			},
+		}
+
+		a, err := dependency.AsSyftArtifact()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Licenses).To(Equal([]string{"Apache-2.0", "MIT", "BSD-3-Clause"}))
+	})
+
+	it("keeps valid SPDX license types", func() {
+		Expect(libpak.BuildpackDependencyLicense{
+			Type: "GPL-2.0-only WITH Classpath-exception-2.0",
+			URI:  "https://openjdk.org/legal/gplv2+ce.html",
+		}.SPDXExpression()).To(Equal("GPL-2.0-only WITH Classpath-exception-2.0"))
+	})
+
 	it("calculates dependency deprecation", func() {
 		deprecatedDependency := libpak.BuildpackDependency{
 			ID:              "test-id",
@@ -171,10 +268,17 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 						"cpes":             []interface{}{"cpe:2.3:a:test-id:1.1.1"},
 						"purl":             "pkg:generic:test-id@1.1.1",
 						"deprecation_date": "2021-12-31T15:59:00-08:00",
+						"source":           "test-source-uri",
+						"source-sha256":    "test-source-sha256",
+						"filename":         "test-filename",
+						"headers":          map[string]interface{}{"Referer": "test-referer"},
 					},
 				},
 				"include-files": []interface{}{"test-include-file"},
 				"pre-package":   "test-pre-package",
+				"version-aliases": map[string]interface{}{
+					"lts": "17.*",
+				},
 			}
 
 			deprecationDate, err := time.Parse(time.RFC3339, "2021-12-31T15:59:00-08:00")
@@ -205,16 +309,126 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 						CPEs:            []string{"cpe:2.3:a:test-id:1.1.1"},
 						PURL:            "pkg:generic:test-id@1.1.1",
 						DeprecationDate: deprecationDate,
+						Source:          "test-source-uri",
+						SourceSHA256:    "test-source-sha256",
+						Filename:        "test-filename",
+						Headers:         map[string]string{"Referer": "test-referer"},
 					},
 				},
-				IncludeFiles: []string{"test-include-file"},
-				PrePackage:   "test-pre-package",
+				IncludeFiles:   []string{"test-include-file"},
+				PrePackage:     "test-pre-package",
+				VersionAliases: map[string]string{"lts": "17.*"},
 			}
 
 			Expect(libpak.NewBuildpackMetadata(actual)).To(Equal(expected))
 		})
 	})
 
+	context("NewBuildpackTargets", func() {
+		var buildpack libcnb.Buildpack
+
+		it.Before(func() {
+			buildpack = libcnb.Buildpack{Path: t.TempDir()}
+		})
+
+		it("reads top-level targets", func() {
+			Expect(os.WriteFile(filepath.Join(buildpack.Path, "buildpack.toml"), []byte(`
+api = "0.7"
+
+[buildpack]
+id = "test-id"
+
+[[targets]]
+os = "linux"
+arch = "arm64"
+variant = "v8"
+
+[[targets]]
+os = "linux"
+arch = "amd64"
+
+[metadata]
+targets = "ignored"
+`), 0644)).To(Succeed())
+
+			Expect(libpak.NewBuildpackTargets(buildpack)).To(Equal([]libpak.BuildpackTarget{
+				{OS: "linux", Arch: "arm64", Variant: "v8"},
+				{OS: "linux", Arch: "amd64"},
+			}))
+		})
+
+		it("configures DependencyResolver targets", func() {
+			Expect(os.WriteFile(filepath.Join(buildpack.Path, "buildpack.toml"), []byte(`
+[[targets]]
+os = "linux"
+arch = "arm64"
+`), 0644)).To(Succeed())
+
+			resolver, err := libpak.NewDependencyResolver(libcnb.BuildContext{Buildpack: buildpack})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolver.Targets).To(Equal([]libpak.BuildpackTarget{{OS: "linux", Arch: "arm64"}}))
+		})
+
+		it("returns no targets without buildpack.toml", func() {
+			Expect(libpak.NewBuildpackTargets(buildpack)).To(BeNil())
+		})
+
+		it("returns an error for malformed targets", func() {
+			Expect(os.WriteFile(filepath.Join(buildpack.Path, "buildpack.toml"), []byte(`
+targets = "linux/amd64"
+`), 0644)).To(Succeed())
+
+			_, err := libpak.NewBuildpackTargets(buildpack)
+			Expect(err).To(MatchError(ContainSubstring("unable to decode targets")))
+		})
+	})
+
+	context("MergeBuildpackMetadata", func() {
+		it("merges configurations and dependencies", func() {
+			first := libpak.BuildpackMetadata{
+				Configurations: []libpak.BuildpackConfiguration{
+					{Name: "TEST_KEY_1", Default: "test-default-1", Description: "first"},
+					{Name: "TEST_KEY_2", Default: "test-default-2"},
+				},
+				Dependencies: []libpak.BuildpackDependency{
+					{ID: "test-id-1", Version: "1.1.1", SHA256: "test-sha256-1"},
+				},
+				PrePackage: "test-pre-package",
+			}
+			second := libpak.BuildpackMetadata{
+				Configurations: []libpak.BuildpackConfiguration{
+					{Name: "TEST_KEY_1", Default: "test-default-1", Description: "second"},
+					{Name: "TEST_KEY_3", Default: "test-default-3"},
+				},
+				Dependencies: []libpak.BuildpackDependency{
+					{ID: "test-id-1", Version: "1.1.1", SHA256: "test-sha256-1"},
+					{ID: "test-id-1", Version: "2.2.2", SHA256: "test-sha256-2"},
+				},
+			}
+
+			Expect(libpak.MergeBuildpackMetadata(first, second)).To(Equal(libpak.BuildpackMetadata{
+				Configurations: []libpak.BuildpackConfiguration{
+					{Name: "TEST_KEY_1", Default: "test-default-1", Description: "first"},
+					{Name: "TEST_KEY_2", Default: "test-default-2"},
+					{Name: "TEST_KEY_3", Default: "test-default-3"},
+				},
+				Dependencies: []libpak.BuildpackDependency{
+					{ID: "test-id-1", Version: "1.1.1", SHA256: "test-sha256-1"},
+					{ID: "test-id-1", Version: "2.2.2", SHA256: "test-sha256-2"},
+				},
+			}))
+		})
+
+		it("fails with conflicting defaults", func() {
+			_, err := libpak.MergeBuildpackMetadata(
+				libpak.BuildpackMetadata{Configurations: []libpak.BuildpackConfiguration{{Name: "TEST_KEY_1", Default: "test-default-1"}}},
+				libpak.BuildpackMetadata{Configurations: []libpak.BuildpackConfiguration{{Name: "TEST_KEY_1", Default: "test-default-2"}}},
+			)
+
+			Expect(err).To(MatchError(`conflicting defaults for configuration TEST_KEY_1: "test-default-1" and "test-default-2"`))
+		})
+	})
+
 	context("ConfigurationResolver", func() {
 		var (
 			resolver = libpak.ConfigurationResolver{
@@ -240,6 +454,39 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 			Expect(os.Unsetenv("TEST_BOOL_2")).To(Succeed())
 		})
 
+		context("WarnUnknown", func() {
+			it("warns about likely typos", func() {
+				t.Setenv("BP_JVM_VERISON", "17")
+				t.Setenv("BP_OTHER_BUILDPACK", "true")
+				t.Setenv("BP_LOG_LEVEL", "info")
+
+				r := libpak.ConfigurationResolver{
+					Configurations: []libpak.BuildpackConfiguration{{Name: "BP_JVM_VERSION"}},
+				}
+
+				b := &bytes.Buffer{}
+				r.WarnUnknown(bard.NewLogger(b), "BP_JVM_TYPE")
+
+				Expect(b.String()).To(ContainSubstring("$BP_JVM_VERISON is not a known configuration, did you mean $BP_JVM_VERSION?"))
+				Expect(b.String()).NotTo(ContainSubstring("BP_OTHER_BUILDPACK"))
+				Expect(b.String()).NotTo(ContainSubstring("$BP_LOG_LEVEL is"))
+			})
+
+			it("does not warn about libpak configurations", func() {
+				for _, name := range []string{"BP_DIALER_NETWORK", "BP_INSECURE_LOCALHOST", "BP_MAX_ARTIFACT_SIZE",
+					"BP_DEPENDENCY_MIRROR_A", "BP_DEPENDENCY_ID_MIRROR_B", "BP_BOM_LABEL_DISABLED"} {
+					t.Setenv(name, "test-value")
+				}
+
+				r := libpak.ConfigurationResolver{}
+
+				b := &bytes.Buffer{}
+				r.WarnUnknown(bard.NewLogger(b))
+
+				Expect(b.String()).To(BeEmpty())
+			})
+		})
+
 		it("returns configured value", func() {
 			v, ok := resolver.Resolve("TEST_KEY_1")
 			Expect(v).To(Equal("test-value-1"))
@@ -275,6 +522,22 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 		it("return false for invalid", func() {
 			Expect(resolver.ResolveBool("TEST_BOOL_6")).To(BeFalse())
 		})
+
+		it("returns lenient bool", func() {
+			for _, v := range []string{"1", "t", "TRUE", "y", "Yes", " on "} {
+				t.Setenv("TEST_BOOL_7", v)
+				Expect(resolver.ResolveBoolLenient("TEST_BOOL_7")).To(BeTrue(), v)
+				Expect(resolver.ResolveBool("TEST_BOOL_7")).To(Equal(v == "1" || v == "t" || v == "TRUE"), v)
+			}
+
+			for _, v := range []string{"0", "f", "false", "n", "NO", "off", "test-value"} {
+				t.Setenv("TEST_BOOL_7", v)
+				Expect(resolver.ResolveBoolLenient("TEST_BOOL_7")).To(BeFalse(), v)
+			}
+
+			Expect(resolver.ResolveBoolLenient("TEST_BOOL_3")).To(BeTrue())
+			Expect(resolver.ResolveBoolLenient("TEST_BOOL_5")).To(BeFalse())
+		})
 	})
 
 	context("DependencyResolver", func() {
@@ -284,10 +547,42 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 
 		it.Before(func() {
 			t.Setenv("BP_ARCH", "amd64") // force for test consistency
+			resolver.Targets = nil
+			resolver.VersionAliases = nil
 		})
 
 		context("Resolve", func() {
 
+			context("targets are declared", func() {
+				it.Before(func() {
+					resolver.Dependencies = []libpak.BuildpackDependency{
+						{
+							ID:      "test-id",
+							Name:    "test-name",
+							Version: "1.0",
+							URI:     "test-uri",
+							SHA256:  "test-sha256",
+							Stacks:  []string{"test-stack-1"},
+							PURL:    "pkg:generic/test-name@1.0?arch=amd64",
+						},
+					}
+					resolver.StackID = "test-stack-1"
+				})
+
+				it("resolves dependency matching a target", func() {
+					resolver.Targets = []libpak.BuildpackTarget{{OS: "linux", Arch: "arm64"}, {OS: "linux", Arch: "amd64"}}
+
+					Expect(resolver.Resolve("test-id", "1.0")).To(HaveField("URI", "test-uri"))
+				})
+
+				it("fails with dependency not matching a target", func() {
+					resolver.Targets = []libpak.BuildpackTarget{{OS: "linux", Arch: "arm64"}}
+
+					_, err := resolver.Resolve("test-id", "1.0")
+					Expect(err).To(MatchError("dependency test-id 1.0 has arch amd64 which does not match any declared target"))
+				})
+			})
+
 			it("filters by id", func() {
 				resolver.Dependencies = []libpak.BuildpackDependency{
 					{
@@ -382,6 +677,31 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 				}))
 			})
 
+			context("version aliases", func() {
+				it.Before(func() {
+					resolver.Dependencies = []libpak.BuildpackDependency{
+						{ID: "test-id", Version: "17.0.1", Stacks: []string{"test-stack-1"}},
+						{ID: "test-id", Version: "21.0.2", Stacks: []string{"test-stack-1"}},
+						{ID: "test-id", Version: "22.0.0-rc1", Stacks: []string{"test-stack-1"}},
+					}
+					resolver.StackID = "test-stack-1"
+					resolver.VersionAliases = map[string]string{"lts": "17.*"}
+				})
+
+				it("resolves latest to the highest stable version", func() {
+					Expect(resolver.Resolve("test-id", "latest")).To(HaveField("Version", "21.0.2"))
+				})
+
+				it("resolves declared alias", func() {
+					Expect(resolver.Resolve("test-id", "lts")).To(HaveField("Version", "17.0.1"))
+				})
+
+				it("fails with unknown alias", func() {
+					_, err := resolver.Resolve("test-id", "stable")
+					Expect(err).To(MatchError(ContainSubstring("invalid constraint stable, it is neither a version constraint nor a known alias")))
+				})
+			})
+
 			it("filters by version constraint", func() {
 				resolver.Dependencies = []libpak.BuildpackDependency{
 					{
@@ -475,6 +795,47 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 				}))
 			})
 
+			it("prefers the specific stack over the wildcard stack for the same version", func() {
+				resolver.Dependencies = []libpak.BuildpackDependency{
+					{
+						ID:      "test-id",
+						Name:    "test-name",
+						Version: "1.0",
+						URI:     "test-uri-wildcard",
+						SHA256:  "test-sha256",
+						Stacks:  []string{"*"},
+					},
+					{
+						ID:      "test-id",
+						Name:    "test-name",
+						Version: "1.0",
+						URI:     "test-uri-specific",
+						SHA256:  "test-sha256",
+						Stacks:  []string{"test-stack-1"},
+					},
+					{
+						ID:      "test-id",
+						Name:    "test-name",
+						Version: "0.9",
+						URI:     "test-uri-older",
+						SHA256:  "test-sha256",
+						Stacks:  []string{"test-stack-1"},
+					},
+				}
+				resolver.StackID = "test-stack-1"
+
+				for i := 0; i < 10; i++ {
+					d, err := resolver.Resolve("test-id", "")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(d.URI).To(Equal("test-uri-specific"))
+				}
+
+				resolver.StackID = "test-stack-2"
+				d, err := resolver.Resolve("test-id", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(d.URI).To(Equal("test-uri-wildcard"))
+			})
+
 			it("filters by stack and treats no stacks as the wildcard stack", func() {
 				resolver.Dependencies = []libpak.BuildpackDependency{
 					{
@@ -639,7 +1000,97 @@ func testBuildpack(t *testing.T, context spec.G, it spec.S) {
 
 				_, err := resolver.Resolve("test-id-2", "1.0")
 				Expect(err).To(HaveOccurred())
-				Expect(err).To(MatchError(libpak.NoValidDependenciesError{Message: "no valid dependencies for test-id-2, 1.0, and test-stack-1 in [(test-id, 1.0, [test-stack-1 test-stack-2]) (test-id, 1.0, [test-stack-1 test-stack-3]) (test-id-2, 1.1, [test-stack-1 test-stack-3])]"}))
+				Expect(err).To(MatchError(libpak.NoValidDependenciesError{Message: "no valid dependencies for test-id-2, 1.0, and test-stack-1 in [(test-id, 1.0, [test-stack-1 test-stack-2]) (test-id, 1.0, [test-stack-1 test-stack-3]) (test-id-2, 1.1, [test-stack-1 test-stack-3])], available: 1.1"}))
+			})
+
+			context("Availability", func() {
+				it.Before(func() {
+					resolver.StackID = "test-stack-1"
+					resolver.Dependencies = nil
+					for _, v := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+						resolver.Dependencies = append(resolver.Dependencies, libpak.BuildpackDependency{
+							ID:      "test-id",
+							Name:    "test-name",
+							Version: v,
+							URI:     "test-uri-" + v,
+							SHA256:  "test-sha256",
+							Stacks:  []string{"test-stack-1"},
+						})
+					}
+				})
+
+				it("falls back to the next highest available version", func() {
+					resolver.Availability = libpak.UnavailableVersions("test-id", "1.2.0")
+
+					d, err := resolver.Resolve("test-id", "1.*")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(d.Version).To(Equal("1.1.0"))
+				})
+
+				it("ignores other dependencies", func() {
+					resolver.Availability = libpak.UnavailableVersions("other-id", "1.2.0")
+
+					d, err := resolver.Resolve("test-id", "1.*")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(d.Version).To(Equal("1.2.0"))
+				})
+
+				it("returns error if no candidates are available", func() {
+					resolver.Availability = libpak.UnavailableVersions("test-id", "1.1.0", "1.2.0")
+
+					_, err := resolver.Resolve("test-id", ">=1.1.0")
+					Expect(libpak.IsNoValidDependencies(err)).To(BeTrue())
+					Expect(err).To(MatchError(HavePrefix("no available dependencies for test-id, >=1.1.0, and test-stack-1")))
+				})
+
+				it("probes availability with HEAD requests", func() {
+					server := ghttp.NewServer()
+					defer server.Close()
+
+					server.RouteToHandler(http.MethodHead, "/1.2.0", ghttp.RespondWith(http.StatusNotFound, ""))
+					server.RouteToHandler(http.MethodHead, "/1.1.0", ghttp.RespondWith(http.StatusOK, ""))
+					for i := range resolver.Dependencies {
+						resolver.Dependencies[i].URI = fmt.Sprintf("%s/%s", server.URL(), resolver.Dependencies[i].Version)
+					}
+					resolver.Availability = libpak.HTTPAvailability(nil)
+
+					d, err := resolver.Resolve("test-id", "1.*")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(d.Version).To(Equal("1.1.0"))
+					Expect(server.ReceivedRequests()).To(HaveLen(2))
+				})
+			})
+
+			it("includes the nearest available versions in the error", func() {
+				for _, v := range []string{"11.0.2", "17.0.7", "17.0.8", "17.0.9"} {
+					resolver.Dependencies = append(resolver.Dependencies, libpak.BuildpackDependency{
+						ID:      "test-id",
+						Name:    "test-name",
+						Version: v,
+						URI:     "test-uri",
+						SHA256:  "test-sha256",
+						Stacks:  []string{"test-stack-1"},
+					})
+				}
+				resolver.Dependencies = append(resolver.Dependencies, libpak.BuildpackDependency{
+					ID:      "test-id",
+					Name:    "test-name",
+					Version: "17.0.10",
+					URI:     "test-uri",
+					SHA256:  "test-sha256",
+					Stacks:  []string{"test-stack-2"},
+				})
+				resolver.StackID = "test-stack-1"
+
+				_, err := resolver.Resolve("test-id", "17.0.99")
+				Expect(libpak.IsNoValidDependencies(err)).To(BeTrue())
+				Expect(err.Error()).To(HaveSuffix(", available: 17.0.8, 17.0.9"))
+
+				_, err = resolver.Resolve("test-id", "17.0.6")
+				Expect(err.Error()).To(HaveSuffix(", available: 11.0.2, 17.0.7, 17.0.8"))
+
+				_, err = resolver.Resolve("test-id", "18.*")
+				Expect(err.Error()).To(HaveSuffix(", available: 17.0.8, 17.0.9"))
 			})
 
 			it("substitutes all wildcard for unspecified version constraint", func() {
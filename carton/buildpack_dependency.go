@@ -20,33 +20,47 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"reflect"
 	"regexp"
+	"sort"
+	"time"
 
-	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/internal"
 )
 
 const (
+	// Deprecated: BuildpackDependency.Update edits buildpack.toml in place, preserving comments and formatting.
 	BuildpackDependencyPattern      = `(?m)([\s]*.*id[\s]+=[\s]+"%s"\n.*\n[\s]*version[\s]+=[\s]+")%s("\n[\s]*uri[\s]+=[\s]+").*("\n[\s]*sha256[\s]+=[\s]+").*(".*)`
 	BuildpackDependencySubstitution = "${1}%s${2}%s${3}%s${4}"
 )
 
 type BuildpackDependency struct {
-	BuildpackPath  string
-	ID             string
-	EolID          string
-	Arch           string
-	SHA256         string
-	URI            string
-	Version        string
-	VersionPattern string
-	CPE            string
-	CPEPattern     string
-	PURL           string
-	PURLPattern    string
-	Source         string `toml:"source,omitempty"`
-	SourceSHA256   string `toml:"source-sha256,omitempty"`
+	BuildpackPath   string
+	ID              string
+	EolID           string
+	DeprecationDate string
+	Arch            string
+	SHA256          string
+	URI             string
+	Version         string
+	VersionPattern  string
+	CPE             string
+	CPEPattern      string
+	PURL            string
+	PURLPattern     string
+	MatchPURL       string
+	Source          string `toml:"source,omitempty"`
+	SourceSHA256    string `toml:"source-sha256,omitempty"`
+
+	// VersionConstraint indicates that VersionPattern is a semver constraint, such as "17.*", rather than a regular
+	// expression.
+	VersionConstraint bool
+
+	// Fields are arbitrary keys, such as name or licenses, that are set on the matched dependencies in addition to the
+	// fields above.
+	Fields map[string]interface{} `toml:"-"`
 }
 
 func (b BuildpackDependency) Update(options ...Option) {
@@ -60,6 +74,7 @@ func (b BuildpackDependency) Update(options ...Option) {
 
 	logger := bard.NewLogger(os.Stdout)
 	_, _ = fmt.Fprintf(logger.TitleWriter(), "\n%s\n", bard.FormatIdentity(b.ID, b.VersionPattern))
+	logger.Headerf("Match PURL:   %s", b.MatchPURL)
 	logger.Headerf("Arch:         %s", b.Arch)
 	logger.Headerf("Version:      %s", b.Version)
 	logger.Headerf("PURL:         %s", b.PURL)
@@ -69,10 +84,29 @@ func (b BuildpackDependency) Update(options ...Option) {
 	logger.Headerf("Source:       %s", b.Source)
 	logger.Headerf("SourceSHA256: %s", b.SourceSHA256)
 	logger.Headerf("EOL ID:       %s", b.EolID)
+	logger.Headerf("Deprecation:  %s", b.DeprecationDate)
 
-	versionExp, err := regexp.Compile(b.VersionPattern)
+	var deprecationDate string
+	if b.DeprecationDate != "" {
+		t, err := time.Parse(time.RFC3339, b.DeprecationDate)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, b.DeprecationDate)
+		}
+		if err != nil {
+			config.exitHandler.Error(fmt.Errorf("unable to parse deprecation date %s\n%w", b.DeprecationDate, err))
+			return
+		}
+		deprecationDate = t.Format(time.RFC3339)
+	}
+
+	newVersionMatcher := libpak.NewVersionExpressionMatcher
+	if b.VersionConstraint {
+		newVersionMatcher = libpak.NewVersionConstraintMatcher
+	}
+
+	versionMatcher, err := newVersionMatcher(b.VersionPattern)
 	if err != nil {
-		config.exitHandler.Error(fmt.Errorf("unable to compile version regex %s\n%w", b.VersionPattern, err))
+		config.exitHandler.Error(fmt.Errorf("unable to compile version pattern %s\n%w", b.VersionPattern, err))
 		return
 	}
 
@@ -94,19 +128,8 @@ func (b BuildpackDependency) Update(options ...Option) {
 		return
 	}
 
-	// save any leading comments, this is to preserve license headers
-	// inline comments will be lost
-	comments := []byte{}
-	for i, line := range bytes.SplitAfter(c, []byte("\n")) {
-		if bytes.HasPrefix(line, []byte("#")) || (i > 0 && len(bytes.TrimSpace(line)) == 0) {
-			comments = append(comments, line...)
-		} else {
-			break // stop on first comment
-		}
-	}
-
 	md := make(map[string]interface{})
-	if err := toml.Unmarshal(c, &md); err != nil {
+	if err := internal.Unmarshal(c, &md); err != nil {
 		config.exitHandler.Error(fmt.Errorf("unable to decode md%s\n%w", b.BuildpackPath, err))
 		return
 	}
@@ -135,7 +158,16 @@ func (b BuildpackDependency) Update(options ...Option) {
 		return
 	}
 
-	for _, dep := range dependencies {
+	changes := make([]map[string]interface{}, len(dependencies))
+	for i, dep := range dependencies {
+		changes[i] = map[string]interface{}{}
+		set := func(key string, value interface{}) {
+			if !reflect.DeepEqual(dep[key], value) {
+				dep[key] = value
+				changes[i][key] = value
+			}
+		}
+
 		depIdUnwrapped, found := dep["id"]
 		if !found {
 			continue
@@ -145,12 +177,22 @@ func (b BuildpackDependency) Update(options ...Option) {
 			continue
 		}
 
+		depVersionUnwrapped, found := dep["version"]
+		if !found {
+			continue
+		}
+		depVersion, ok := depVersionUnwrapped.(string)
+		if !ok {
+			continue
+		}
+
 		// extract the arch from the PURL, it's the only place it lives consistently at the moment
-		var depArch string
+		var depArch, depPURL string
 		purlUnwrapped, found := dep["purl"]
 		if found {
 			purl, ok := purlUnwrapped.(string)
 			if ok {
+				depPURL = purl
 				purlArchExp := regexp.MustCompile(`arch=(.*)`)
 				purlArchMatches := purlArchExp.FindStringSubmatch(purl)
 				if len(purlArchMatches) == 2 {
@@ -165,77 +207,127 @@ func (b BuildpackDependency) Update(options ...Option) {
 			depArch = "amd64"
 		}
 
-		if depId == b.ID && depArch == b.Arch {
-
-			depVersionUnwrapped, found := dep["version"]
-			if !found {
+		if b.MatchPURL != "" {
+			if depPURL != b.MatchPURL {
 				continue
 			}
+		} else if depId != b.ID || depArch != b.Arch || !versionMatcher.Matches(depVersion) {
+			continue
+		}
 
-			depVersion, ok := depVersionUnwrapped.(string)
-			if !ok {
-				continue
-			}
+		// without explicit patterns, the current version of the dependency is replaced
+		depPURLExp, depCPEExp := purlExp, cpeExp
+		if b.PURLPattern == "" {
+			depPURLExp = regexp.MustCompile(regexp.QuoteMeta(depVersion))
+		}
+		if b.CPEPattern == "" {
+			depCPEExp = regexp.MustCompile(regexp.QuoteMeta(depVersion))
+		}
 
-			if versionExp.MatchString(depVersion) {
-				dep["version"] = b.Version
-				dep["uri"] = b.URI
-				dep["sha256"] = b.SHA256
-				if b.SourceSHA256 != "" {
-					dep["source-sha256"] = b.SourceSHA256
-				}
-				if b.Source != "" {
-					dep["source"] = b.Source
-				}
+		set("version", b.Version)
+		set("uri", b.URI)
+		set("sha256", b.SHA256)
+		if b.SourceSHA256 != "" {
+			set("source-sha256", b.SourceSHA256)
+		}
+		if b.Source != "" {
+			set("source", b.Source)
+		}
 
-				purlUnwrapped, found := dep["purl"]
-				if found {
-					purl, ok := purlUnwrapped.(string)
-					if ok {
-						dep["purl"] = purlExp.ReplaceAllString(purl, b.PURL)
-					}
-				}
+		if depPURL != "" {
+			set("purl", depPURLExp.ReplaceAllString(depPURL, b.PURL))
+		}
 
-				cpesUnwrapped, found := dep["cpes"]
-				if found {
-					cpes, ok := cpesUnwrapped.([]interface{})
-					if ok {
-						for i := 0; i < len(cpes); i++ {
-							cpe, ok := cpes[i].(string)
-							if !ok {
-								continue
-							}
-
-							cpes[i] = cpeExp.ReplaceAllString(cpe, b.CPE)
-						}
+		cpesUnwrapped, found := dep["cpes"]
+		if found {
+			cpes, ok := cpesUnwrapped.([]interface{})
+			if ok {
+				updated := make([]interface{}, len(cpes))
+				for j, c := range cpes {
+					updated[j] = c
+					if cpe, ok := c.(string); ok {
+						updated[j] = depCPEExp.ReplaceAllString(cpe, b.CPE)
 					}
 				}
+				set("cpes", updated)
+			}
+		}
 
-				if b.EolID != "" {
-					eolDate, err := internal.GetEolDate(b.EolID, b.Version)
-					if err != nil {
-						config.exitHandler.Error(fmt.Errorf("unable to fetch deprecation_date"))
-						return
-					}
+		if b.EolID != "" {
+			eolDate, err := internal.GetEolDate(b.EolID, b.Version)
+			if err != nil {
+				config.exitHandler.Error(fmt.Errorf("unable to fetch deprecation_date"))
+				return
+			}
 
-					if eolDate != "" {
-						dep["deprecation_date"] = eolDate
-					}
-				}
+			if eolDate != "" {
+				set("deprecation_date", eolDate)
 			}
 		}
+
+		if deprecationDate != "" {
+			set("deprecation_date", deprecationDate)
+		}
+
+		for k, v := range b.Fields {
+			set(k, v)
+		}
 	}
 
-	c, err = internal.Marshal(md)
+	c, err = updateDependencies(c, md, changes)
 	if err != nil {
 		config.exitHandler.Error(fmt.Errorf("unable to encode md %s\n%w", b.BuildpackPath, err))
 		return
 	}
 
-	c = append(comments, c...)
-
 	if err := os.WriteFile(b.BuildpackPath, c, 0644); err != nil {
 		config.exitHandler.Error(fmt.Errorf("unable to write %s\n%w", b.BuildpackPath, err))
 		return
 	}
 }
+
+// updateDependencies applies the changes to each of the [[metadata.dependencies]] in content, preserving comments and
+// formatting.  If the dependencies are not declared as an array of tables, md is encoded in full instead and only
+// leading comments, such as license headers, are preserved.
+func updateDependencies(content []byte, md map[string]interface{}, changes []map[string]interface{}) ([]byte, error) {
+	doc := newTOMLDocument(content)
+
+	if len(doc.tables("metadata.dependencies")) != len(changes) {
+		comments := []byte{}
+		for i, line := range bytes.SplitAfter(internal.NormalizeTOML(content), []byte("\n")) {
+			if bytes.HasPrefix(line, []byte("#")) || (i > 0 && len(bytes.TrimSpace(line)) == 0) {
+				comments = append(comments, line...)
+			} else {
+				break // stop on first comment
+			}
+		}
+
+		c, err := internal.Marshal(md)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(comments, c...), nil
+	}
+
+	for i, change := range changes {
+		keys := make([]string, 0, len(change))
+		for k := range change {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v, err := encodeTOMLValue(change[k])
+			if err != nil {
+				return nil, err
+			}
+
+			if err := doc.Set("metadata.dependencies", i, k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return doc.Bytes(), nil
+}
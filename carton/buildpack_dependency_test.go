@@ -103,6 +103,75 @@ source-sha256 = "test-source-sha256-2"
 `))
 	})
 
+	it("matches the version pattern as a regular expression", func() {
+		Expect(os.WriteFile(path, []byte(`[[metadata.dependencies]]
+id      = "test-id"
+version = "2023-01-05"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "2023-02-01",
+			VersionPattern: "2023.*",
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(os.ReadFile(path)).To(internal.MatchTOML(`[[metadata.dependencies]]
+id      = "test-id"
+version = "2023-02-01"
+uri     = "test-uri-2"
+sha256  = "test-sha256-2"
+`))
+	})
+
+	it("matches the version pattern as a semver constraint", func() {
+		Expect(os.WriteFile(path, []byte(`[[metadata.dependencies]]
+id      = "test-id"
+version = "17.0.1"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+
+[[metadata.dependencies]]
+id      = "test-id"
+version = "11.0.2"
+uri     = "test-uri-3"
+sha256  = "test-sha256-3"
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:     path,
+			ID:                "test-id",
+			Arch:              "amd64",
+			SHA256:            "test-sha256-2",
+			URI:               "test-uri-2",
+			Version:           "17.0.2",
+			VersionPattern:    "17.*",
+			VersionConstraint: true,
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(os.ReadFile(path)).To(internal.MatchTOML(`[[metadata.dependencies]]
+id      = "test-id"
+version = "17.0.2"
+uri     = "test-uri-2"
+sha256  = "test-sha256-2"
+
+[[metadata.dependencies]]
+id      = "test-id"
+version = "11.0.2"
+uri     = "test-uri-3"
+sha256  = "test-sha256-3"
+`))
+	})
+
 	it("updates dependency with purl & cpes", func() {
 		Expect(os.WriteFile(path, []byte(`api = "0.7"
 [buildpack]
@@ -501,4 +570,404 @@ version = "1.2.3"
   stacks        = [ "test-stack" ]
 `))
 	})
+
+	it("updates dependency deprecation date", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id               = "test-id"
+name             = "Test Name"
+version          = "test-version-1"
+uri              = "test-uri-1"
+sha256           = "test-sha256-1"
+stacks           = [ "test-stack" ]
+deprecation_date = "2020-01-01T00:00:00Z"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "other-version-1"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+stacks  = [ "test-stack" ]
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:   path,
+			ID:              "test-id",
+			Arch:            "amd64",
+			SHA256:          "test-sha256-2",
+			URI:             "test-uri-2",
+			Version:         "test-version-2",
+			VersionPattern:  `test-version-[\d]`,
+			DeprecationDate: "2030-06-30",
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(internal.MatchTOML(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id               = "test-id"
+name             = "Test Name"
+version          = "test-version-2"
+uri              = "test-uri-2"
+sha256           = "test-sha256-2"
+stacks           = [ "test-stack" ]
+deprecation_date = "2030-06-30T00:00:00Z"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "other-version-1"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+stacks  = [ "test-stack" ]
+`))
+	})
+
+	it("fails on an invalid deprecation date", func() {
+		d := carton.BuildpackDependency{
+			BuildpackPath:   path,
+			ID:              "test-id",
+			VersionPattern:  `test-version-[\d]`,
+			DeprecationDate: "not-a-date",
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(HavePrefix("unable to parse deprecation date not-a-date")))
+	})
+
+	it("updates only the dependency matching a purl", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "1.0.0"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+purl    = "pkg:generic/test-jre@1.0.0?arch=amd64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.0.0:*:*:*:*:*:*:*"]
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "1.0.0"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+purl    = "pkg:generic/test-jre@1.0.0?arch=arm64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.0.0:*:*:*:*:*:*:*"]
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath: path,
+			MatchPURL:     "pkg:generic/test-jre@1.0.0?arch=arm64",
+			SHA256:        "test-sha256-2",
+			URI:           "test-uri-2",
+			Version:       "2.0.0",
+			PURL:          "2.0.0",
+			CPE:           "2.0.0",
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(internal.MatchTOML(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "1.0.0"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1"
+purl    = "pkg:generic/test-jre@1.0.0?arch=amd64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.0.0:*:*:*:*:*:*:*"]
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "2.0.0"
+uri     = "test-uri-2"
+sha256  = "test-sha256-2"
+purl    = "pkg:generic/test-jre@2.0.0?arch=arm64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:2.0.0:*:*:*:*:*:*:*"]
+`))
+	})
+
+	it("preserves comments and formatting", func() {
+		Expect(os.WriteFile(path, []byte(`# Copyright header
+
+api = "0.7"
+
+[buildpack]
+id = "some-buildpack" # the id
+version = "1.2.3"
+
+# the dependencies
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"  # the name
+version = "test-version-1"
+uri     = "test-uri-1"
+sha256  = "test-sha256-1" # the sha256
+cpes    = [
+  "cpe:2.3:a:test-vendor:test-product:test-version-1:*:*:*:*:*:*:*",
+]
+
+  [[metadata.dependencies.licenses]]
+  type = "Apache-2.0"
+  uri  = "https://www.apache.org/licenses/"
+
+[[metadata.dependencies]]
+id      = "other-id"
+version = "test-version-1" # unchanged
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+			CPEPattern:     `test-version-[\d]`,
+			CPE:            "test-version-2",
+			Fields: map[string]interface{}{
+				"name":     "New Name",
+				"custom":   int64(1),
+				"licenses": []map[string]interface{}{{"type": "MIT"}},
+			},
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte(`# Copyright header
+
+api = "0.7"
+
+[buildpack]
+id = "some-buildpack" # the id
+version = "1.2.3"
+
+# the dependencies
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "New Name"  # the name
+version = "test-version-2"
+uri     = "test-uri-2"
+sha256  = "test-sha256-2" # the sha256
+cpes    = ["cpe:2.3:a:test-vendor:test-product:test-version-2:*:*:*:*:*:*:*"]
+custom = 1
+licenses = [{ type = "MIT" }]
+
+[[metadata.dependencies]]
+id      = "other-id"
+version = "test-version-1" # unchanged
+`)))
+	})
+
+	it("updates a dependency with a byte order mark and CRLF line endings", func() {
+		Expect(os.WriteFile(path, []byte("\xEF\xBB\xBF# Copyright header\r\n"+
+			"api = \"0.7\"\r\n"+
+			"\r\n"+
+			"[[metadata.dependencies]]\r\n"+
+			"id      = \"test-id\"\r\n"+
+			"version = \"test-version-1\" # the version\r\n"+
+			"uri     = \"test-uri-1\"\r\n"+
+			"sha256  = \"test-sha256-1\"\r\n"), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte("\xEF\xBB\xBF# Copyright header\r\n" +
+			"api = \"0.7\"\r\n" +
+			"\r\n" +
+			"[[metadata.dependencies]]\r\n" +
+			"id      = \"test-id\"\r\n" +
+			"version = \"test-version-2\" # the version\r\n" +
+			"uri     = \"test-uri-2\"\r\n" +
+			"sha256  = \"test-sha256-2\"\r\n")))
+	})
+
+	it("replaces multi-line arrays and adds keys with CRLF line endings", func() {
+		Expect(os.WriteFile(path, []byte("api = \"0.7\"\r\n"+
+			"\r\n"+
+			"[[metadata.dependencies]]\r\n"+
+			"id      = \"test-id\"\r\n"+
+			"version = \"test-version-1\"\r\n"+
+			"uri     = \"test-uri-1\"\r\n"+
+			"sha256  = \"test-sha256-1\"\r\n"+
+			"cpes    = [\r\n"+
+			"  \"cpe:2.3:a:test-vendor:test-product:test-version-1:*:*:*:*:*:*:*\", # the cpe\r\n"+
+			"]\r\n"+
+			"\r\n"+
+			"[[metadata.dependencies]]\r\n"+
+			"id      = \"other-id\"\r\n"), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+			CPEPattern:     `test-version-[\d]`,
+			CPE:            "test-version-2",
+			Fields:         map[string]interface{}{"custom": "value"},
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte("api = \"0.7\"\r\n" +
+			"\r\n" +
+			"[[metadata.dependencies]]\r\n" +
+			"id      = \"test-id\"\r\n" +
+			"version = \"test-version-2\"\r\n" +
+			"uri     = \"test-uri-2\"\r\n" +
+			"sha256  = \"test-sha256-2\"\r\n" +
+			"cpes    = [\"cpe:2.3:a:test-vendor:test-product:test-version-2:*:*:*:*:*:*:*\"]\r\n" +
+			"custom = \"value\"\r\n" +
+			"\r\n" +
+			"[[metadata.dependencies]]\r\n" +
+			"id      = \"other-id\"\r\n")))
+	})
+
+	it("replaces inline tables", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[[metadata.dependencies]]
+id       = "test-id"
+version  = "test-version-1"
+uri      = "test-uri-1"
+sha256   = "test-sha256-1"
+licenses = [
+  { type = "Apache-2.0", uri = "https://example.com/[licenses]#apache" },
+  { type = "BSD-3-Clause" },
+]
+source   = { uri = "test-source-uri-1", "sha256" = "test-source-sha256-1" } # the source
+stacks   = ["*"]
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+			Fields: map[string]interface{}{
+				"licenses": []map[string]interface{}{{"type": "MIT"}},
+				"source":   map[string]interface{}{"uri": "test-source-uri-2", "sha256": "test-source-sha256-2"},
+			},
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte(`api = "0.7"
+
+[[metadata.dependencies]]
+id       = "test-id"
+version  = "test-version-2"
+uri      = "test-uri-2"
+sha256   = "test-sha256-2"
+licenses = [{ type = "MIT" }]
+source   = { sha256 = "test-source-sha256-2", uri = "test-source-uri-2" } # the source
+stacks   = ["*"]
+`)))
+	})
+
+	it("replaces values of quoted keys", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+
+[[ "metadata" . 'dependencies' ]]
+"id"             = "test-id"
+'version'        = "test-version-1" # the version
+"uri"            = "test-uri-1"
+"sha256"         = "test-sha256-1"
+"source-sha256"  = "test-source-sha256-1"
+"name # not a comment" = "unchanged"
+`), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+			Fields: map[string]interface{}{
+				"source-sha256": "test-source-sha256-2",
+				"with space":    "added",
+			},
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(os.ReadFile(path)).To(Equal([]byte(`api = "0.7"
+
+[[ "metadata" . 'dependencies' ]]
+"id"             = "test-id"
+'version'        = "test-version-2" # the version
+"uri"            = "test-uri-2"
+"sha256"         = "test-sha256-2"
+"source-sha256"  = "test-source-sha256-2"
+"name # not a comment" = "unchanged"
+"with space" = "added"
+`)))
+	})
+
+	it("fails with the position of invalid TOML", func() {
+		Expect(os.WriteFile(path, []byte("api = \"0.7\"\r\n[[metadata.dependencies]]\r\nid = invalid\r\n"), 0644)).To(Succeed())
+
+		d := carton.BuildpackDependency{
+			BuildpackPath:  path,
+			ID:             "test-id",
+			Arch:           "amd64",
+			SHA256:         "test-sha256-2",
+			URI:            "test-uri-2",
+			Version:        "test-version-2",
+			VersionPattern: `test-version-[\d]`,
+		}
+
+		d.Update(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(And(ContainSubstring(path), ContainSubstring("At line 3"))))
+	})
 }
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/internal"
+)
+
+var sha256Pattern = regexp.MustCompile(`^(?:(?:sha256:)?[a-f0-9]{64}|sha384:[a-f0-9]{96}|sha512:[a-f0-9]{128})$`)
+
+// BuildpackValidator validates the dependency metadata in a buildpack.toml.
+type BuildpackValidator struct {
+
+	// BuildpackPath is the path to the buildpack.toml to validate.
+	BuildpackPath string
+}
+
+// Validate checks every dependency in the buildpack.toml and reports all problems that are found.  Each dependency
+// must have a PURL, well-formed CPEs, a SHA256, a semver version, and a URI with a supported scheme.
+func (b BuildpackValidator) Validate(options ...Option) {
+	config := Config{
+		exitHandler: internal.NewExitHandler(),
+	}
+
+	for _, option := range options {
+		config = option(config)
+	}
+
+	logger := bard.NewLogger(os.Stdout)
+	_, _ = fmt.Fprintf(logger.TitleWriter(), "\n%s\n", bard.FormatIdentity("Validating", b.BuildpackPath))
+
+	c, err := os.ReadFile(b.BuildpackPath)
+	if err != nil {
+		config.exitHandler.Error(fmt.Errorf("unable to read %s\n%w", b.BuildpackPath, err))
+		return
+	}
+
+	buildpack := libcnb.Buildpack{}
+	if err := internal.Unmarshal(c, &buildpack); err != nil {
+		config.exitHandler.Error(fmt.Errorf("unable to decode buildpack %s\n%w", b.BuildpackPath, err))
+		return
+	}
+
+	metadata, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
+	if err != nil {
+		config.exitHandler.Error(fmt.Errorf("unable to decode metadata %s\n%w", b.BuildpackPath, err))
+		return
+	}
+
+	count := 0
+	for _, dep := range metadata.Dependencies {
+		problems := validateDependency(dep)
+		if len(problems) == 0 {
+			logger.Headerf("%s %s", color.GreenString("Valid"), dep.ID)
+			continue
+		}
+
+		logger.Headerf("%s %s", color.RedString("Invalid"), dep.ID)
+		for _, p := range problems {
+			logger.Body(p)
+		}
+		count += len(problems)
+	}
+
+	if count > 0 {
+		config.exitHandler.Error(fmt.Errorf("found %d problems in %s", count, b.BuildpackPath))
+		return
+	}
+}
+
+func validateDependency(dep libpak.BuildpackDependency) []string {
+	var problems []string
+
+	if dep.PURL == "" {
+		problems = append(problems, "purl must be set")
+	} else if !strings.HasPrefix(dep.PURL, "pkg:") || !strings.Contains(dep.PURL, "/") {
+		problems = append(problems, fmt.Sprintf("purl %q is not of the form pkg:type/name@version", dep.PURL))
+	}
+
+	for _, cpe := range dep.CPEs {
+		if !strings.HasPrefix(cpe, "cpe:2.3:") || len(strings.Split(cpe, ":")) != 13 {
+			problems = append(problems, fmt.Sprintf("cpe %q is not a well-formed CPE 2.3 string", cpe))
+		}
+	}
+
+	if dep.SHA256 == "" {
+		problems = append(problems, "sha256 must be set")
+	} else if !sha256Pattern.MatchString(dep.SHA256) {
+		problems = append(problems, fmt.Sprintf("sha256 %q is not a hex encoded SHA256 or sha384:/sha512: checksum", dep.SHA256))
+	}
+
+	if _, err := semver.NewVersion(dep.Version); err != nil {
+		problems = append(problems, fmt.Sprintf("version %q cannot be parsed\n%s", dep.Version, err))
+	}
+
+	if u, err := url.Parse(dep.URI); err != nil {
+		problems = append(problems, fmt.Sprintf("uri %q cannot be parsed\n%s", dep.URI, err))
+	} else if s := strings.ToLower(u.Scheme); s != "https" && s != "http" && s != "file" {
+		problems = append(problems, fmt.Sprintf("uri %q does not use one of the schemes https, http, or file", dep.URI))
+	}
+
+	return problems
+}
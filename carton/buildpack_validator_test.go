@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb/mocks"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/paketo-buildpacks/libpak/carton"
+)
+
+func testBuildpackValidator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		exitHandler *mocks.ExitHandler
+		path        string
+	)
+
+	it.Before(func() {
+		exitHandler = &mocks.ExitHandler{}
+		exitHandler.On("Error", mock.Anything)
+
+		path = filepath.Join(t.TempDir(), "buildpack.toml")
+	})
+
+	it("accepts valid dependencies", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "Test Name"
+version = "1.2.3"
+uri     = "https://test-host/test-path"
+sha256  = "0000000000000000000000000000000000000000000000000000000000000000"
+purl    = "pkg:generic/test-id@1.2.3?arch=amd64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.2.3:*:*:*:*:*:*:*"]
+
+[[metadata.dependencies]]
+id      = "test-id-2"
+name    = "Test Name"
+version = "1.2.3"
+uri     = "https://test-host/test-path"
+sha256  = "sha512:00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+purl    = "pkg:generic/test-id-2@1.2.3?arch=amd64"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.2.3:*:*:*:*:*:*:*"]
+`), 0644)).To(Succeed())
+
+		carton.BuildpackValidator{BuildpackPath: path}.Validate(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+	})
+
+	it("reports all problems", func() {
+		Expect(os.WriteFile(path, []byte(`api = "0.7"
+[buildpack]
+id = "some-buildpack"
+name = "Some Buildpack"
+version = "1.2.3"
+
+[[metadata.dependencies]]
+id      = "test-id-1"
+name    = "Test Name"
+version = "not-a-version"
+uri     = "ftp://test-host/test-path"
+purl    = "generic/test-id@1.2.3"
+cpes    = ["cpe:2.3:a:test-vendor:test-product:1.2.3"]
+
+[[metadata.dependencies]]
+id      = "test-id-2"
+name    = "Test Name"
+version = "1.2.3"
+uri     = "https://test-host/test-path"
+sha256  = "test-sha256"
+`), 0644)).To(Succeed())
+
+		carton.BuildpackValidator{BuildpackPath: path}.Validate(carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(HaveLen(1))
+		Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring("found 7 problems")))
+	})
+}
@@ -36,6 +36,7 @@ type Config struct {
 	entryWriter EntryWriter
 	executor    effect.Executor
 	exitHandler libcnb.ExitHandler
+	signer      Signer
 }
 
 // Option is a function for configuring a Config instance.
@@ -64,3 +65,11 @@ func WithExitHandler(exitHandler libcnb.ExitHandler) Option {
 		return config
 	}
 }
+
+// WithSigner creates an Option that signs the manifest of each package that is created.
+func WithSigner(signer Signer) Option {
+	return func(config Config) Config {
+		config.signer = signer
+		return config
+	}
+}
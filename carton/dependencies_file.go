@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/internal"
+)
+
+// readDependenciesFile reads the dependencies array of a TOML or JSON file.  Each dependency has the same format as
+// metadata.dependencies in buildpack.toml, and is returned in the form that decoding buildpack.toml as TOML produces.
+func readDependenciesFile(path string) ([]map[string]interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("unable to decode %s as JSON\n%w", path, err)
+		}
+	} else {
+		if err := internal.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("unable to decode %s as TOML\n%w", path, err)
+		}
+	}
+
+	dependencies, ok := normalizeTables(raw["dependencies"]).([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unable to find dependencies array in %s", path)
+	}
+
+	return dependencies, nil
+}
+
+// normalizeTables converts every array whose elements are all tables to []map[string]interface{}, as TOML decoding
+// does, so that JSON decoded values can be used in place of TOML decoded ones.
+func normalizeTables(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeTables(e)
+		}
+		return v
+	case []map[string]interface{}:
+		for i, e := range v {
+			v[i] = normalizeTables(e).(map[string]interface{})
+		}
+		return v
+	case []interface{}:
+		var tables []map[string]interface{}
+		for i, e := range v {
+			v[i] = normalizeTables(e)
+			if t, ok := v[i].(map[string]interface{}); ok {
+				tables = append(tables, t)
+			}
+		}
+		if len(v) > 0 && len(tables) == len(v) {
+			return tables
+		}
+		return v
+	default:
+		return v
+	}
+}
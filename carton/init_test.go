@@ -27,9 +27,11 @@ func TestUnit(t *testing.T) {
 	suite := spec.New("libpak/carton", spec.Report(report.Terminal{}))
 	suite("BuildpackDependency", testBuildpackDependency)
 	suite("BuildImageDependency", testBuildImageDependency)
+	suite("BuildpackValidator", testBuildpackValidator)
 	suite("LifecycleDependency", testLifecycleDependency)
 	suite("Netrc", testNetrc)
 	suite("Package", testPackage)
 	suite("PackageDependency", testPackageDependency)
+	suite("Signature", testSignature)
 	suite.Run(t)
 }
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// ManifestEntry describes a single file written to a package.
+type ManifestEntry struct {
+
+	// Path is the path of the file within the package.
+	Path string `json:"path"`
+
+	// Source is the path the file was copied from.
+	Source string `json:"source"`
+
+	// SHA256 is the hash of the dependency the file belongs to, if any.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// PURL is the package URL of the dependency the file belongs to, if any.
+	PURL string `json:"purl,omitempty"`
+}
+
+// writeManifest writes a JSON array of ManifestEntry, one for each of the files, to path.
+func writeManifest(path string, entries map[string]string, files []string, dependencies map[string]libpak.BuildpackDependency) error {
+	manifest := []ManifestEntry{}
+	for _, f := range files {
+		e := ManifestEntry{Path: f, Source: entries[f]}
+
+		if dep, ok := dependencies[f]; ok {
+			e.SHA256 = dep.SHA256
+			e.PURL = dep.PURL
+		}
+
+		manifest = append(manifest, e)
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode manifest\n%w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(path), err)
+	}
+
+	if err := sherpa.WriteFileAtomic(path, b, 0644); err != nil {
+		return fmt.Errorf("unable to write manifest %s\n%w", path, err)
+	}
+
+	return nil
+}
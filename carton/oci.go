@@ -0,0 +1,217 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+
+	"github.com/paketo-buildpacks/libpak/crush"
+)
+
+const (
+	// DirectoryFormat writes the package as a directory tree.
+	DirectoryFormat = "dir"
+
+	// OCIFormat writes the package as an OCI image layout archive.
+	OCIFormat = "oci"
+)
+
+const (
+	ociConfigMediaType   = "application/vnd.oci.image.config.v1+json"
+	ociLayerMediaType    = "application/vnd.oci.image.layer.v1.tar"
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+)
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// buildpackLayerPath returns the path of a buildpack within a buildpackage layer.
+func buildpackLayerPath(buildpack libcnb.Buildpack) string {
+	return filepath.Join("cnb", "buildpacks", strings.ReplaceAll(buildpack.Info.ID, "/", "_"), buildpack.Info.Version)
+}
+
+// writeOCIArchive writes an OCI image layout archive to destination.  The image has a single layer containing the
+// contents of source, which is expected to already be laid out under buildpackLayerPath, and the labels that identify
+// it as a buildpackage.
+func writeOCIArchive(buildpack libcnb.Buildpack, arch string, source string, destination string) error {
+	layer, err := os.CreateTemp("", "carton-layer-*.tar")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary layer file\n%w", err)
+	}
+	defer os.Remove(layer.Name())
+	defer layer.Close()
+
+	s := sha256.New()
+	if err := crush.CreateTar(io.MultiWriter(layer, s), source); err != nil {
+		return fmt.Errorf("unable to create layer from %s\n%w", source, err)
+	}
+
+	info, err := layer.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat %s\n%w", layer.Name(), err)
+	}
+
+	layerDescriptor := ociDescriptor{
+		MediaType: ociLayerMediaType,
+		Digest:    fmt.Sprintf("sha256:%s", hex.EncodeToString(s.Sum(nil))),
+		Size:      info.Size(),
+	}
+
+	var stacks []map[string]interface{}
+	for _, stack := range buildpack.Stacks {
+		stacks = append(stacks, map[string]interface{}{"id": stack.ID, "mixins": stack.Mixins})
+	}
+
+	metadataLabel, err := json.Marshal(map[string]interface{}{
+		"id":       buildpack.Info.ID,
+		"version":  buildpack.Info.Version,
+		"homepage": buildpack.Info.Homepage,
+		"stacks":   stacks,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode buildpackage metadata\n%w", err)
+	}
+
+	layersLabel, err := json.Marshal(map[string]interface{}{
+		buildpack.Info.ID: map[string]interface{}{
+			buildpack.Info.Version: map[string]interface{}{
+				"api":         buildpack.API,
+				"stacks":      stacks,
+				"layerDiffID": layerDescriptor.Digest,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode buildpack layers\n%w", err)
+	}
+
+	if arch == DefaultTargetArch {
+		arch = "amd64"
+	}
+
+	config, err := json.Marshal(map[string]interface{}{
+		"architecture": arch,
+		"os":           "linux",
+		"config": map[string]interface{}{
+			"Labels": map[string]string{
+				"io.buildpacks.buildpackage.metadata": string(metadataLabel),
+				"io.buildpacks.buildpack.layers":      string(layersLabel),
+			},
+		},
+		"rootfs": map[string]interface{}{
+			"type":     "layers",
+			"diff_ids": []string{layerDescriptor.Digest},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode image config\n%w", err)
+	}
+	configDescriptor := ociBlobDescriptor(ociConfigMediaType, config)
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociManifestMediaType,
+		"config":        configDescriptor,
+		"layers":        []ociDescriptor{layerDescriptor},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode image manifest\n%w", err)
+	}
+	manifestDescriptor := ociBlobDescriptor(ociManifestMediaType, manifest)
+
+	index, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests":     []ociDescriptor{manifestDescriptor},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode image index\n%w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	t := tar.NewWriter(out)
+
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{
+		{"oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+		{"index.json", index},
+		{ociBlobPath(configDescriptor), config},
+		{ociBlobPath(manifestDescriptor), manifest},
+	} {
+		if err := t.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content))}); err != nil {
+			return fmt.Errorf("unable to write header for %s\n%w", f.name, err)
+		}
+		if _, err := t.Write(f.content); err != nil {
+			return fmt.Errorf("unable to write %s\n%w", f.name, err)
+		}
+	}
+
+	if _, err := layer.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind %s\n%w", layer.Name(), err)
+	}
+
+	name := ociBlobPath(layerDescriptor)
+	if err := t.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: layerDescriptor.Size}); err != nil {
+		return fmt.Errorf("unable to write header for %s\n%w", name, err)
+	}
+	if _, err := io.Copy(t, layer); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", name, err)
+	}
+
+	if err := t.Close(); err != nil {
+		return fmt.Errorf("unable to close %s\n%w", destination, err)
+	}
+
+	return nil
+}
+
+func ociBlobDescriptor(mediaType string, content []byte) ociDescriptor {
+	s := sha256.Sum256(content)
+
+	return ociDescriptor{
+		MediaType: mediaType,
+		Digest:    fmt.Sprintf("sha256:%s", hex.EncodeToString(s[:])),
+		Size:      int64(len(content)),
+	}
+}
+
+func ociBlobPath(descriptor ociDescriptor) string {
+	return fmt.Sprintf("blobs/sha256/%s", strings.TrimPrefix(descriptor.Digest, "sha256:"))
+}
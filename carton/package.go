@@ -22,10 +22,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/libcnb"
 	"github.com/heroku/color"
 
@@ -46,9 +47,14 @@ type Package struct {
 	// DependencyFilters indicates which filters should be applied to exclude dependencies
 	DependencyFilters []string
 
-	// StrictDependencyFilters indicates that a filter must match both the ID and version, otherwise it must only match one of the two
+	// StrictDependencyFilters indicates that a filter must match all of the DependencyFilterFields, otherwise it must
+	// only match one of them
 	StrictDependencyFilters bool
 
+	// DependencyFilterFields are the fields of a dependency that DependencyFilters are matched against: any of "id",
+	// "version", "purl", and "arch".  Default is "id" and "version".
+	DependencyFilterFields []string
+
 	// IncludeDependencies indicates whether to include dependencies in build package.
 	IncludeDependencies bool
 
@@ -61,14 +67,43 @@ type Package struct {
 	// Version is a version to substitute into an existing buildpack.toml.
 	Version string
 
-	// TargetArch is the target architecture to package. Default is "all".
+	// TargetArch is the target architecture to package. Default is "all".  Multiple architectures may be given as a
+	// comma separated list, in which case each architecture is packaged into a subdirectory of Destination.
 	TargetArch string
+
+	// StrictChecksums indicates that packaging should fail if a dependency does not have a SHA256 to verify it with.
+	StrictChecksums bool
+
+	// Format is the format of the package, either DirectoryFormat or OCIFormat.  Default is DirectoryFormat.  When
+	// OCIFormat is used, Destination is the path of the OCI image layout archive to create.
+	Format string
+
+	// DryRun indicates that the entries of the package should be logged, but that nothing should be written to the
+	// destination.
+	DryRun bool
+
+	// EmitManifest is the path to write a JSON manifest of the packaged entries to once packaging has completed.  Each
+	// entry lists the path of the file within the package, its source path, and the SHA256 and PURL of the dependency it
+	// belongs to.  No manifest is written if it is empty.
+	EmitManifest string
+
+	// DependenciesFile is the path of a TOML or JSON file with a top-level dependencies array, in the same format as
+	// metadata.dependencies in buildpack.toml.  Its dependencies are merged with those declared in buildpack.toml
+	// before packaging.  A file ending in .json is decoded as JSON, any other file as TOML.
+	DependenciesFile string
+
+	// ModificationTime is the time to set as the modification time of every file and directory written, so that
+	// packaging is reproducible.  If zero, the time is read from the SOURCE_DATE_EPOCH environment variable as seconds
+	// since the Unix epoch and if that is not set, entries keep the time they are written at.
+	ModificationTime time.Time
 }
 
 // Create creates a package.
 func (p Package) Create(options ...Option) {
+	modificationTime, timeErr := p.modificationTime()
+
 	config := Config{
-		entryWriter: internal.EntryWriter{},
+		entryWriter: internal.EntryWriter{ModificationTime: modificationTime},
 		executor:    effect.NewExecutor(),
 		exitHandler: internal.NewExitHandler(),
 	}
@@ -77,6 +112,11 @@ func (p Package) Create(options ...Option) {
 		config = option(config)
 	}
 
+	if timeErr != nil {
+		config.exitHandler.Error(timeErr)
+		return
+	}
+
 	var (
 		err  error
 		file string
@@ -91,12 +131,30 @@ func (p Package) Create(options ...Option) {
 		config.exitHandler.Error(fmt.Errorf("unable to read %s\n%w", file, err))
 		return
 	}
-	if err := toml.Unmarshal(b, &buildpack); err != nil {
+	if err := internal.Unmarshal(b, &buildpack); err != nil {
 		config.exitHandler.Error(fmt.Errorf("unable to decode buildpack %s\n%w", file, err))
 		return
 	}
 	logger.Debugf("Buildpack: %+v", buildpack)
 
+	if p.DependenciesFile != "" {
+		dependencies, err := readDependenciesFile(p.DependenciesFile)
+		if err != nil {
+			config.exitHandler.Error(fmt.Errorf("unable to read dependencies file\n%w", err))
+			return
+		}
+
+		logger.Debugf("Merging %d dependencies from %s", len(dependencies), p.DependenciesFile)
+
+		if buildpack.Metadata == nil {
+			buildpack.Metadata = map[string]interface{}{}
+		}
+		if existing, ok := buildpack.Metadata["dependencies"].([]map[string]interface{}); ok {
+			dependencies = append(existing, dependencies...)
+		}
+		buildpack.Metadata["dependencies"] = dependencies
+	}
+
 	metadata, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
 	if err != nil {
 		config.exitHandler.Error(fmt.Errorf("unable to decode metadata %s\n%w", buildpack.Metadata, err))
@@ -126,6 +184,7 @@ func (p Package) Create(options ...Option) {
 	logger.Debugf("Supported targets: %+v", supportedTargets)
 
 	entries := map[string]string{}
+	dependencies := map[string]libpak.BuildpackDependency{}
 
 	for _, i := range metadata.IncludeFiles {
 		if oldOutputFormat || strings.HasPrefix(i, "linux/") || i == "buildpack.toml" {
@@ -163,15 +222,21 @@ func (p Package) Create(options ...Option) {
 	}
 
 	logger.Title(buildpack)
-	logger.Headerf("Creating package in %s", p.Destination)
+	if p.DryRun {
+		logger.Headerf("Planning package in %s (dry run)", p.Destination)
+	} else {
+		logger.Headerf("Creating package in %s", p.Destination)
 
-	if err = os.RemoveAll(p.Destination); err != nil {
-		config.exitHandler.Error(fmt.Errorf("unable to remove destination path %s\n%w", p.Destination, err))
-		return
+		if err = os.RemoveAll(p.Destination); err != nil {
+			config.exitHandler.Error(fmt.Errorf("unable to remove destination path %s\n%w", p.Destination, err))
+			return
+		}
 	}
 
 	file = metadata.PrePackage
-	if file != "" {
+	if file != "" && p.DryRun {
+		logger.Headerf("Would run pre-package %s", file)
+	} else if file != "" {
 		logger.Headerf("Pre-package with %s", file)
 		execution := effect.Execution{
 			Command: file,
@@ -185,6 +250,14 @@ func (p Package) Create(options ...Option) {
 		}
 	}
 
+	// include-files may be generated by the pre-package script, which is not run during a dry run
+	if err := validateIncludeFiles(entries); err != nil && p.DryRun {
+		logger.Bodyf("%s %s", color.YellowString("Warning:"), err)
+	} else if err != nil {
+		config.exitHandler.Error(err)
+		return
+	}
+
 	if p.IncludeDependencies {
 		cache := libpak.DependencyCache{
 			Logger:    logger,
@@ -210,11 +283,29 @@ func (p Package) Create(options ...Option) {
 		}
 
 		for _, dep := range metadata.Dependencies {
-			if !p.matchDependency(dep) {
+			match, err := p.matchDependency(dep)
+			if err != nil {
+				config.exitHandler.Error(fmt.Errorf("unable to filter %s %s\n%w", dep.ID, dep.Version, err))
+				return
+			}
+
+			if !match {
 				logger.Bodyf("Skipping [%s or %s] which matched a filter", dep.ID, dep.Version)
 				continue
 			}
 
+			if p.StrictChecksums && dep.SHA256 == "" {
+				config.exitHandler.Error(fmt.Errorf("unable to verify %s %s, dependency has no sha256", dep.ID, dep.Version))
+				return
+			}
+
+			if p.DryRun {
+				artifact := filepath.Join(cache.DownloadPath, plannedChecksum(dep), plannedArtifactName(dep))
+				logger.Headerf("Would cache %s", color.BlueString("%s %s", dep.Name, dep.Version))
+				p.addDependencyEntries(entries, dependencies, dep, artifact)
+				continue
+			}
+
 			logger.Headerf("Caching %s", color.BlueString("%s %s", dep.Name, dep.Version))
 
 			f, err := cache.Artifact(dep, n.BasicAuth)
@@ -228,8 +319,7 @@ func (p Package) Create(options ...Option) {
 				return
 			}
 
-			entries[fmt.Sprintf("dependencies/%s/%s", dep.SHA256, filepath.Base(f.Name()))] = f.Name()
-			entries[fmt.Sprintf("dependencies/%s.toml", dep.SHA256)] = fmt.Sprintf("%s.toml", filepath.Dir(f.Name()))
+			p.addDependencyEntries(entries, dependencies, dep, f.Name())
 		}
 	}
 
@@ -238,41 +328,297 @@ func (p Package) Create(options ...Option) {
 		files = append(files, d)
 	}
 	sort.Strings(files)
+
+	targetArches := p.targetArches()
+	for _, targetArch := range targetArches {
+		destination := p.Destination
+		if len(targetArches) > 1 {
+			destination = filepath.Join(p.Destination, targetArch)
+			logger.Headerf("Adding %s entries to %s", targetArch, destination)
+		}
+
+		var written []packageEntry
+		if p.Format == OCIFormat {
+			written, err = p.writeOCI(config, logger, buildpack, entries, files, oldOutputFormat, targetArch, destination, modificationTime)
+		} else {
+			written, err = p.writeEntries(config, logger, entries, files, oldOutputFormat, targetArch, destination, modificationTime)
+		}
+		if err != nil {
+			config.exitHandler.Error(err)
+			return
+		}
+
+		if config.signer != nil {
+			if p.DryRun {
+				logger.Bodyf("Would sign %s", destination)
+				continue
+			}
+
+			logger.Bodyf("Signing %s", destination)
+			if err := writeSignature(config.signer, destination, written); err != nil {
+				config.exitHandler.Error(err)
+				return
+			}
+		}
+	}
+
+	if p.EmitManifest != "" {
+		if p.DryRun {
+			logger.Headerf("Would write manifest to %s", p.EmitManifest)
+			return
+		}
+
+		logger.Headerf("Writing manifest to %s", p.EmitManifest)
+		if err := writeManifest(p.EmitManifest, entries, files, dependencies); err != nil {
+			config.exitHandler.Error(err)
+			return
+		}
+	}
+}
+
+// addDependencyEntries adds the entries for the artifact of a dependency and its metadata in the cache.
+func (Package) addDependencyEntries(entries map[string]string, dependencies map[string]libpak.BuildpackDependency,
+	dep libpak.BuildpackDependency, artifact string) {
+
+	checksum := filepath.Base(filepath.Dir(artifact))
+
+	artifactEntry := fmt.Sprintf("dependencies/%s/%s", checksum, filepath.Base(artifact))
+	entries[artifactEntry] = artifact
+	dependencies[artifactEntry] = dep
+
+	metadataEntry := fmt.Sprintf("dependencies/%s.toml", checksum)
+	entries[metadataEntry] = fmt.Sprintf("%s.toml", filepath.Dir(artifact))
+	dependencies[metadataEntry] = dep
+}
+
+// plannedChecksum returns the name of the cache directory a dependency is expected to be downloaded to.  Without a
+// SHA256 it is only known once downloaded.
+func plannedChecksum(dep libpak.BuildpackDependency) string {
+	if dep.SHA256 == "" {
+		return "<sha256>"
+	}
+
+	return libpak.ChecksumDirectory(dep.SHA256)
+}
+
+// plannedArtifactName returns the name a dependency is expected to be downloaded as, without downloading it.
+func plannedArtifactName(dep libpak.BuildpackDependency) string {
+	if dep.Filename != "" {
+		return filepath.Base(dep.Filename)
+	}
+
+	return filepath.Base(dep.URI)
+}
+
+// writeOCI writes the entries for a single target architecture to an OCI image layout archive at destination.
+func (p Package) writeOCI(config Config, logger bard.Logger, buildpack libcnb.Buildpack, entries map[string]string,
+	files []string, oldOutputFormat bool, targetArch string, destination string, modificationTime time.Time) ([]packageEntry, error) {
+
+	staging, err := os.MkdirTemp("", "carton-oci-*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create staging directory\n%w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	root := filepath.Join(staging, buildpackLayerPath(buildpack))
+	written, err := p.writeEntries(config, logger, entries, files, oldOutputFormat, targetArch, root, modificationTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.DryRun {
+		if err := setDirectoryTimes(staging, modificationTime); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.DryRun {
+		logger.Bodyf("Would write OCI image to %s", destination)
+		return written, nil
+	}
+
+	logger.Bodyf("Writing OCI image to %s", destination)
+	if err := writeOCIArchive(buildpack, targetArch, staging, destination); err != nil {
+		return nil, fmt.Errorf("unable to write OCI image %s\n%w", destination, err)
+	}
+
+	return written, nil
+}
+
+// validateIncludeFiles returns an error listing every entry source that does not exist.
+func validateIncludeFiles(entries map[string]string) error {
+	missing := map[string]bool{}
+	for _, source := range entries {
+		if _, err := os.Stat(source); os.IsNotExist(err) {
+			missing[source] = true
+		} else if err != nil {
+			return fmt.Errorf("unable to stat %s\n%w", source, err)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var sources []string
+	for source := range missing {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	return fmt.Errorf("unable to find include-files:\n  %s", strings.Join(sources, "\n  "))
+}
+
+// targetArches returns the architectures listed in TargetArch, which may be a comma separated list.
+func (p Package) targetArches() []string {
+	var targetArches []string
+	for _, a := range strings.Split(p.TargetArch, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			targetArches = append(targetArches, a)
+		}
+	}
+
+	if len(targetArches) == 0 {
+		return []string{DefaultTargetArch}
+	}
+
+	return targetArches
+}
+
+// writeEntries writes the entries for a single target architecture to destination, and returns the entries that are
+// part of the package.
+func (p Package) writeEntries(config Config, logger bard.Logger, entries map[string]string, files []string,
+	oldOutputFormat bool, targetArch string, destination string, modificationTime time.Time) ([]packageEntry, error) {
+
+	var written []packageEntry
+
 	for _, d := range files {
-		if p.TargetArch != DefaultTargetArch && !oldOutputFormat && strings.HasPrefix(d, "linux/") && !strings.HasPrefix(d, fmt.Sprintf("linux/%s", p.TargetArch)) {
-			logger.Debugf("Skipping %s because target arch is %s", d, p.TargetArch)
+		if targetArch != DefaultTargetArch && !oldOutputFormat && strings.HasPrefix(d, "linux/") && !strings.HasPrefix(d, fmt.Sprintf("linux/%s", targetArch)) {
+			logger.Debugf("Skipping %s because target arch is %s", d, targetArch)
 			continue
 		}
 
 		targetLocation := d
-		if p.TargetArch != DefaultTargetArch {
-			targetLocation = strings.Replace(d, fmt.Sprintf("linux/%s/", p.TargetArch), "", 1)
+		if targetArch != DefaultTargetArch {
+			targetLocation = strings.Replace(d, fmt.Sprintf("linux/%s/", targetArch), "", 1)
+		}
+
+		written = append(written, packageEntry{path: targetLocation, source: entries[d]})
+
+		file := filepath.Join(destination, targetLocation)
+		if p.DryRun {
+			logger.Bodyf("Would add %s -> %s", entries[d], file)
+			continue
 		}
 
 		logger.Bodyf("Adding %s", targetLocation)
-		file = filepath.Join(p.Destination, targetLocation)
-		if err = config.entryWriter.Write(entries[d], file); err != nil {
-			config.exitHandler.Error(fmt.Errorf("unable to write file %s to %s\n%w", entries[d], file, err))
-			return
+		if err := config.entryWriter.Write(entries[d], file); err != nil {
+			return nil, fmt.Errorf("unable to write file %s to %s\n%w", entries[d], file, err)
 		}
 	}
+
+	if p.DryRun {
+		return written, nil
+	}
+
+	return written, setDirectoryTimes(destination, modificationTime)
+}
+
+// modificationTime returns the ModificationTime of the package, or the time in SOURCE_DATE_EPOCH if it is not set.
+func (p Package) modificationTime() (time.Time, error) {
+	if !p.ModificationTime.IsZero() {
+		return p.ModificationTime, nil
+	}
+
+	s, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || s == "" {
+		return time.Time{}, nil
+	}
+
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse SOURCE_DATE_EPOCH %s\n%w", s, err)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// setDirectoryTimes sets the access and modification time of root and every directory beneath it to modificationTime.
+// Directories are set once all entries are written, as writing an entry changes the modification time of its
+// directory.  If modificationTime is zero, times are not changed.
+func setDirectoryTimes(root string, modificationTime time.Time) error {
+	if modificationTime.IsZero() {
+		return nil
+	}
+
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if err := os.Chtimes(path, modificationTime, modificationTime); err != nil {
+			return fmt.Errorf("unable to set times of %s\n%w", path, err)
+		}
+
+		return nil
+	})
 }
 
 // matchDependency checks all filters against dependency and returns true if there is a match (or no filters) and false if there is no match
-// There is a match if a regular expression matches against the ID or Version
-func (p Package) matchDependency(dep libpak.BuildpackDependency) bool {
+// There is a match if a regular expression matches against any of the DependencyFilterFields, or all of them if
+// StrictDependencyFilters is set
+func (p Package) matchDependency(dep libpak.BuildpackDependency) (bool, error) {
 	if len(p.DependencyFilters) == 0 {
-		return true
+		return true, nil
+	}
+
+	fields := p.DependencyFilterFields
+	if len(fields) == 0 {
+		fields = []string{"id", "version"}
+	}
+
+	var values []string
+	for _, field := range fields {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "id":
+			values = append(values, dep.ID)
+		case "version":
+			values = append(values, dep.Version)
+		case "purl":
+			values = append(values, dep.PURL)
+		case "arch":
+			arch, err := dep.Arch()
+			if err != nil {
+				return false, fmt.Errorf("unable to determine arch\n%w", err)
+			}
+			values = append(values, arch)
+		default:
+			return false, fmt.Errorf("unknown dependency filter field %s", field)
+		}
 	}
 
 	for _, rawFilter := range p.DependencyFilters {
 		filter := regexp.MustCompile(rawFilter)
 
-		if (p.StrictDependencyFilters && filter.MatchString(dep.ID) && filter.MatchString(dep.Version)) ||
-			(!p.StrictDependencyFilters && (filter.MatchString(dep.ID) || filter.MatchString(dep.Version))) {
-			return true
+		matches := 0
+		for _, v := range values {
+			if filter.MatchString(v) {
+				matches++
+			}
+		}
+
+		if (p.StrictDependencyFilters && matches == len(values)) || (!p.StrictDependencyFilters && matches > 0) {
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
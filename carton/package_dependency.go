@@ -22,7 +22,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/BurntSushi/toml"
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/internal"
 )
@@ -143,6 +142,7 @@ func updateFile(cfgPath string, f func(md map[string]interface{})) error {
 	if err != nil {
 		return fmt.Errorf("unable to read %s\n%w", cfgPath, err)
 	}
+	c = internal.NormalizeTOML(c)
 
 	// save any leading comments, this is to preserve license headers
 	// inline comments will be lost
@@ -156,7 +156,7 @@ func updateFile(cfgPath string, f func(md map[string]interface{})) error {
 	}
 
 	md := make(map[string]interface{})
-	if err := toml.Unmarshal(c, &md); err != nil {
+	if err := internal.Unmarshal(c, &md); err != nil {
 		return fmt.Errorf("unable to decode md %s\n%w", cfgPath, err)
 	}
 
@@ -17,9 +17,16 @@
 package carton_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/buildpacks/libcnb/mocks"
 	. "github.com/onsi/gomega"
@@ -80,6 +87,7 @@ include-files = [
   "buildpack.toml",
 ]
 `), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "test-include-files"), []byte{}, 0644)).To(Succeed())
 	})
 
 	it.After(func() {
@@ -131,6 +139,11 @@ include-files = [
   "linux/arm64/bin/also-just-once"
 ]
 `), 0644)).To(Succeed())
+
+			for _, f := range []string{"README", "LICENSE", "linux/amd64/bin/just-once", "linux/arm64/bin/also-just-once"} {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(path, f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(path, f), []byte{}, 0644)).To(Succeed())
+			}
 		})
 
 		it("includes include_files using the original format", func() {
@@ -159,6 +172,37 @@ include-files = [
 			Expect(entryWriter.Calls[6].Arguments[0]).To(Equal(filepath.Join(path, "linux/arm64/bin/also-just-once")))
 			Expect(entryWriter.Calls[6].Arguments[1]).To(Equal(filepath.Join("test-destination", "linux/arm64/bin/also-just-once")))
 		})
+
+		it("packages each of multiple target arches into its own directory", func() {
+			carton.Package{
+				Source:      path,
+				Destination: "test-destination",
+				TargetArch:  "amd64,arm64",
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(entryWriter.Calls).To(HaveLen(8))
+
+			Expect(entryWriter.Calls[0].Arguments[0]).To(Equal(filepath.Join(path, "buildpack.toml")))
+			Expect(entryWriter.Calls[0].Arguments[1]).To(Equal(filepath.Join("test-destination", "amd64", "buildpack.toml")))
+			Expect(entryWriter.Calls[1].Arguments[0]).To(Equal(filepath.Join(path, "LICENSE")))
+			Expect(entryWriter.Calls[1].Arguments[1]).To(Equal(filepath.Join("test-destination", "amd64", "LICENSE")))
+			Expect(entryWriter.Calls[2].Arguments[0]).To(Equal(filepath.Join(path, "README")))
+			Expect(entryWriter.Calls[2].Arguments[1]).To(Equal(filepath.Join("test-destination", "amd64", "README")))
+			Expect(entryWriter.Calls[3].Arguments[0]).To(Equal(filepath.Join(path, "linux/amd64/bin/just-once")))
+			Expect(entryWriter.Calls[3].Arguments[1]).To(Equal(filepath.Join("test-destination", "amd64", "bin/just-once")))
+
+			Expect(entryWriter.Calls[4].Arguments[0]).To(Equal(filepath.Join(path, "buildpack.toml")))
+			Expect(entryWriter.Calls[4].Arguments[1]).To(Equal(filepath.Join("test-destination", "arm64", "buildpack.toml")))
+			Expect(entryWriter.Calls[5].Arguments[0]).To(Equal(filepath.Join(path, "LICENSE")))
+			Expect(entryWriter.Calls[5].Arguments[1]).To(Equal(filepath.Join("test-destination", "arm64", "LICENSE")))
+			Expect(entryWriter.Calls[6].Arguments[0]).To(Equal(filepath.Join(path, "README")))
+			Expect(entryWriter.Calls[6].Arguments[1]).To(Equal(filepath.Join("test-destination", "arm64", "README")))
+			Expect(entryWriter.Calls[7].Arguments[0]).To(Equal(filepath.Join(path, "linux/arm64/bin/also-just-once")))
+			Expect(entryWriter.Calls[7].Arguments[1]).To(Equal(filepath.Join("test-destination", "arm64", "bin/also-just-once")))
+		})
 	})
 
 	it("includes include_files using the target format", func() {
@@ -176,6 +220,293 @@ include-files = [
 		Expect(entryWriter.Calls[1].Arguments[1]).To(Equal(filepath.Join("test-destination", "test-include-files")))
 	})
 
+	it("does not write entries during a dry run", func() {
+		destination := t.TempDir()
+		Expect(os.WriteFile(filepath.Join(destination, "existing"), []byte{}, 0644)).To(Succeed())
+
+		carton.Package{
+			Source:      path,
+			Destination: destination,
+			DryRun:      true,
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		Expect(entryWriter.Calls).To(BeEmpty())
+		Expect(filepath.Join(destination, "existing")).To(BeARegularFile())
+	})
+
+	it("does not run the pre-package script during a dry run", func() {
+		carton.Package{
+			Source:      path,
+			Destination: t.TempDir(),
+			DryRun:      true,
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		executor.AssertNotCalled(t, "Execute", mock.Anything)
+	})
+
+	it("fails for missing include files", func() {
+		Expect(os.Remove(filepath.Join(path, "test-include-files"))).To(Succeed())
+
+		carton.Package{
+			Source:      path,
+			Destination: "test-destination",
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring(filepath.Join(path, "test-include-files"))))
+		Expect(entryWriter.Calls).To(BeEmpty())
+	})
+
+	it("does not fail for missing include files during a dry run", func() {
+		Expect(os.Remove(filepath.Join(path, "test-include-files"))).To(Succeed())
+
+		carton.Package{
+			Source:      path,
+			Destination: t.TempDir(),
+			DryRun:      true,
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		exitHandler.AssertNotCalled(t, "Error", mock.Anything)
+	})
+
+	it("writes an OCI image layout archive", func() {
+		Expect(os.WriteFile(filepath.Join(path, "test-include-files"), []byte("test-content"), 0644)).To(Succeed())
+		destination := filepath.Join(t.TempDir(), "test-buildpack.oci")
+
+		carton.Package{
+			Source:      path,
+			Destination: destination,
+			Format:      carton.OCIFormat,
+			Version:     "1.2.3",
+		}.Create(
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+
+		in, err := os.Open(destination)
+		Expect(err).NotTo(HaveOccurred())
+		defer in.Close()
+
+		var names []string
+		r := tar.NewReader(in)
+		for {
+			h, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			names = append(names, h.Name)
+		}
+
+		Expect(names).To(HaveLen(5))
+		Expect(names[0]).To(Equal("oci-layout"))
+		Expect(names[1]).To(Equal("index.json"))
+		for _, name := range names[2:] {
+			Expect(name).To(HavePrefix("blobs/sha256/"))
+		}
+	})
+
+	context("with a modification time", func() {
+		var modificationTime = time.Unix(1577836800, 0)
+
+		it("sets the modification time of every entry", func() {
+			destination := t.TempDir()
+
+			carton.Package{
+				Source:           path,
+				Destination:      destination,
+				ModificationTime: modificationTime,
+			}.Create(
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+
+			for _, f := range []string{"", "buildpack.toml", "test-include-files"} {
+				s, err := os.Stat(filepath.Join(destination, f))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(s.ModTime().Equal(modificationTime)).To(BeTrue(), f)
+			}
+		})
+
+		it("reads the modification time from SOURCE_DATE_EPOCH", func() {
+			t.Setenv("SOURCE_DATE_EPOCH", "1577836800")
+			destination := t.TempDir()
+
+			carton.Package{
+				Source:      path,
+				Destination: destination,
+			}.Create(
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+
+			s, err := os.Stat(filepath.Join(destination, "buildpack.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.ModTime().Equal(modificationTime)).To(BeTrue())
+		})
+
+		it("fails with an invalid SOURCE_DATE_EPOCH", func() {
+			t.Setenv("SOURCE_DATE_EPOCH", "test-epoch")
+
+			carton.Package{
+				Source:      path,
+				Destination: "test-destination",
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring("unable to parse SOURCE_DATE_EPOCH test-epoch")))
+			Expect(entryWriter.Calls).To(BeEmpty())
+		})
+
+		it("writes identical OCI image layout archives", func() {
+			destinations := []string{
+				filepath.Join(t.TempDir(), "test-buildpack.oci"),
+				filepath.Join(t.TempDir(), "test-buildpack.oci"),
+			}
+
+			for _, destination := range destinations {
+				carton.Package{
+					Source:           path,
+					Destination:      destination,
+					Format:           carton.OCIFormat,
+					Version:          "1.2.3",
+					ModificationTime: modificationTime,
+				}.Create(
+					carton.WithExecutor(executor),
+					carton.WithExitHandler(exitHandler))
+
+				time.Sleep(time.Second)
+			}
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+
+			expected, err := os.ReadFile(destinations[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(destinations[1])).To(Equal(expected))
+		})
+	})
+
+	context("with a signer", func() {
+		var manifests [][]byte
+
+		signer := func(manifest []byte) ([]byte, error) {
+			manifests = append(manifests, manifest)
+			return []byte("test-signature"), nil
+		}
+
+		it.Before(func() {
+			manifests = nil
+			Expect(os.WriteFile(filepath.Join(path, "test-include-files"), []byte("test-content"), 0644)).To(Succeed())
+		})
+
+		it("writes a signed manifest alongside the package", func() {
+			destination := filepath.Join(t.TempDir(), "test-destination")
+
+			carton.Package{
+				Source:      path,
+				Destination: destination,
+			}.Create(
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler),
+				carton.WithSigner(signer))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+			Expect(manifests).To(HaveLen(1))
+
+			b, err := os.ReadFile(filepath.Join(path, "buildpack.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			expected := fmt.Sprintf("%x  buildpack.toml\n%x  test-include-files\n",
+				sha256.Sum256(b), sha256.Sum256([]byte("test-content")))
+			Expect(string(manifests[0])).To(Equal(expected))
+
+			Expect(os.ReadFile(fmt.Sprintf("%s.manifest", destination))).To(Equal([]byte(expected)))
+			Expect(os.ReadFile(fmt.Sprintf("%s.sig", destination))).To(Equal([]byte("test-signature")))
+		})
+
+		it("does not sign during a dry run", func() {
+			destination := filepath.Join(t.TempDir(), "test-destination")
+
+			carton.Package{
+				Source:      path,
+				Destination: destination,
+				DryRun:      true,
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler),
+				carton.WithSigner(signer))
+
+			Expect(manifests).To(BeEmpty())
+			Expect(fmt.Sprintf("%s.sig", destination)).NotTo(BeAnExistingFile())
+		})
+
+		it("fails when signing fails", func() {
+			carton.Package{
+				Source:      path,
+				Destination: filepath.Join(t.TempDir(), "test-destination"),
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler),
+				carton.WithSigner(func([]byte) ([]byte, error) {
+					return nil, fmt.Errorf("test-error")
+				}))
+
+			Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring("unable to sign package manifest")))
+		})
+	})
+
+	it("decodes a buildpack.toml with a byte order mark and CRLF line endings", func() {
+		b, err := os.ReadFile(filepath.Join(path, "buildpack.toml"))
+		Expect(err).NotTo(HaveOccurred())
+		b = append([]byte("\xEF\xBB\xBF"), bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n"))...)
+		Expect(os.WriteFile(filepath.Join(path, "buildpack.toml"), b, 0644)).To(Succeed())
+
+		carton.Package{
+			Source:      path,
+			Destination: "test-destination",
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls).To(BeEmpty())
+		Expect(entryWriter.Calls[1].Arguments[0]).To(Equal(filepath.Join(path, "test-include-files")))
+	})
+
+	it("fails with the position of invalid TOML in buildpack.toml", func() {
+		Expect(os.WriteFile(filepath.Join(path, "buildpack.toml"), []byte("api = \"0.0.0\"\r\n[buildpack]\r\nname = invalid\r\n"), 0644)).To(Succeed())
+
+		carton.Package{
+			Source:      path,
+			Destination: "test-destination",
+		}.Create(
+			carton.WithEntryWriter(entryWriter),
+			carton.WithExecutor(executor),
+			carton.WithExitHandler(exitHandler))
+
+		Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(And(
+			ContainSubstring(filepath.Join(path, "buildpack.toml")),
+			ContainSubstring("At line 3"))))
+		Expect(entryWriter.Calls).To(BeEmpty())
+	})
+
 	it("replaces .version in buildpack.toml", func() {
 		carton.Package{
 			Source:      path,
@@ -234,6 +565,7 @@ name    = "test-name"
 version = "1.1.1"
 uri     = "test-uri-1"
 sha256  = "test-sha256-1"
+purl    = "pkg:generic/test@1.1.1?arch=arm64"
 
 [[metadata.dependencies]]
 id      = "test-id"
@@ -241,6 +573,7 @@ name    = "test-name"
 version = "2.0.5"
 uri     = "test-uri-2"
 sha256  = "test-sha256-2"
+purl    = "pkg:generic/test@2.0.5?arch=amd64"
 
 [[metadata.dependencies]]
 id      = "another-test-id"
@@ -258,6 +591,24 @@ include-files = [
 `), 0644)).To(Succeed())
 		})
 
+		it("does not download dependencies during a dry run", func() {
+			cache := t.TempDir()
+
+			carton.Package{
+				Source:              path,
+				Destination:         t.TempDir(),
+				IncludeDependencies: true,
+				CacheLocation:       cache,
+				DryRun:              true,
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			exitHandler.AssertNotCalled(t, "Error", mock.Anything)
+			Expect(os.ReadDir(cache)).To(BeEmpty())
+		})
+
 		it("includes all dependencies", func() {
 			carton.Package{
 				Source:              path,
@@ -292,6 +643,170 @@ include-files = [
 			Expect(entryWriter.Calls[7].Arguments[1]).To(Equal(filepath.Join("test-destination", "test-include-files")))
 		})
 
+		it("emits a manifest", func() {
+			manifest := filepath.Join(t.TempDir(), "manifest.json")
+
+			carton.Package{
+				Source:              path,
+				Destination:         "test-destination",
+				IncludeDependencies: true,
+				CacheLocation:       "testdata",
+				DependencyFilters:   []string{`^another-test-id$`},
+				EmitManifest:        manifest,
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+
+			b, err := os.ReadFile(manifest)
+			Expect(err).NotTo(HaveOccurred())
+
+			var entries []carton.ManifestEntry
+			Expect(json.Unmarshal(b, &entries)).To(Succeed())
+			Expect(entries).To(Equal([]carton.ManifestEntry{
+				{Path: "buildpack.toml", Source: filepath.Join(path, "buildpack.toml")},
+				{Path: "dependencies/test-sha256-3.toml", Source: "testdata/test-sha256-3.toml", SHA256: "test-sha256-3"},
+				{Path: "dependencies/test-sha256-3/test-uri-3", Source: "testdata/test-sha256-3/test-uri-3", SHA256: "test-sha256-3"},
+				{Path: "test-include-files", Source: filepath.Join(path, "test-include-files")},
+			}))
+		})
+
+		context("with a dependencies file", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(path, "buildpack.toml"), []byte(`
+api = "0.0.0"
+
+[buildpack]
+name    = "test-name"
+version = "{{.version}}"
+
+[[metadata.dependencies]]
+id      = "another-test-id"
+name    = "test-name"
+version = "1.1.1"
+uri     = "test-uri-3"
+sha256  = "test-sha256-3"
+
+[metadata]
+include-files = [
+  "test-include-files",
+  "buildpack.toml",
+]
+`), 0644)).To(Succeed())
+			})
+
+			it("merges dependencies from JSON", func() {
+				file := filepath.Join(t.TempDir(), "dependencies.json")
+				Expect(os.WriteFile(file, []byte(`{"dependencies": [
+  {"id": "test-id", "name": "test-name", "version": "1.1.1", "uri": "test-uri-1", "sha256": "test-sha256-1", "purl": "pkg:generic/test@1.1.1?arch=arm64"}
+]}`), 0644)).To(Succeed())
+
+				carton.Package{
+					Source:              path,
+					Destination:         "test-destination",
+					IncludeDependencies: true,
+					CacheLocation:       "testdata",
+					DependenciesFile:    file,
+				}.Create(
+					carton.WithEntryWriter(entryWriter),
+					carton.WithExecutor(executor),
+					carton.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.Calls).To(BeEmpty())
+				Expect(entryWriter.Calls).To(HaveLen(6))
+				Expect(entryWriter.Calls[1].Arguments[0]).To(Equal("testdata/test-sha256-1.toml"))
+				Expect(entryWriter.Calls[3].Arguments[0]).To(Equal("testdata/test-sha256-3.toml"))
+			})
+
+			it("merges dependencies from TOML", func() {
+				file := filepath.Join(t.TempDir(), "dependencies.toml")
+				Expect(os.WriteFile(file, []byte(`
+[[dependencies]]
+id      = "test-id"
+name    = "test-name"
+version = "2.0.5"
+uri     = "test-uri-2"
+sha256  = "test-sha256-2"
+purl    = "pkg:generic/test@2.0.5?arch=amd64"
+`), 0644)).To(Succeed())
+
+				carton.Package{
+					Source:              path,
+					Destination:         "test-destination",
+					IncludeDependencies: true,
+					CacheLocation:       "testdata",
+					DependenciesFile:    file,
+				}.Create(
+					carton.WithEntryWriter(entryWriter),
+					carton.WithExecutor(executor),
+					carton.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.Calls).To(BeEmpty())
+				Expect(entryWriter.Calls).To(HaveLen(6))
+				Expect(entryWriter.Calls[1].Arguments[0]).To(Equal("testdata/test-sha256-2.toml"))
+				Expect(entryWriter.Calls[3].Arguments[0]).To(Equal("testdata/test-sha256-3.toml"))
+			})
+
+			it("fails without a dependencies array", func() {
+				file := filepath.Join(t.TempDir(), "dependencies.json")
+				Expect(os.WriteFile(file, []byte(`{}`), 0644)).To(Succeed())
+
+				carton.Package{
+					Source:              path,
+					Destination:         "test-destination",
+					IncludeDependencies: true,
+					CacheLocation:       "testdata",
+					DependenciesFile:    file,
+				}.Create(
+					carton.WithEntryWriter(entryWriter),
+					carton.WithExecutor(executor),
+					carton.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring("unable to find dependencies array")))
+			})
+		})
+
+		context("with strict checksums", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(path, "buildpack.toml"), []byte(`
+api = "0.0.0"
+
+[buildpack]
+name    = "test-name"
+version = "{{.version}}"
+
+[[metadata.dependencies]]
+id      = "test-id"
+name    = "test-name"
+version = "1.1.1"
+uri     = "test-uri-1"
+
+[metadata]
+include-files = [
+  "buildpack.toml",
+]
+`), 0644)).To(Succeed())
+			})
+
+			it("fails for dependencies without sha256", func() {
+				carton.Package{
+					Source:              path,
+					Destination:         "test-destination",
+					IncludeDependencies: true,
+					CacheLocation:       "testdata",
+					StrictChecksums:     true,
+				}.Create(
+					carton.WithEntryWriter(entryWriter),
+					carton.WithExecutor(executor),
+					carton.WithExitHandler(exitHandler))
+
+				Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError("unable to verify test-id 1.1.1, dependency has no sha256"))
+				Expect(entryWriter.Calls).To(BeEmpty())
+			})
+		})
+
 		it("includes filter by id", func() {
 			carton.Package{
 				Source:              path,
@@ -347,6 +862,61 @@ include-files = [
 			Expect(entryWriter.Calls[5].Arguments[1]).To(Equal(filepath.Join("test-destination", "test-include-files")))
 		})
 
+		it("includes filter by arch", func() {
+			carton.Package{
+				Source:                 path,
+				Destination:            "test-destination",
+				IncludeDependencies:    true,
+				CacheLocation:          "testdata",
+				DependencyFilters:      []string{`^arm64$`},
+				DependencyFilterFields: []string{"arch"},
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+			Expect(entryWriter.Calls).To(HaveLen(4))
+			Expect(entryWriter.Calls[1].Arguments[0]).To(Equal("testdata/test-sha256-1.toml"))
+			Expect(entryWriter.Calls[2].Arguments[0]).To(Equal("testdata/test-sha256-1/test-uri-1"))
+		})
+
+		it("includes filter by purl and id", func() {
+			carton.Package{
+				Source:                  path,
+				Destination:             "test-destination",
+				IncludeDependencies:     true,
+				CacheLocation:           "testdata",
+				DependencyFilters:       []string{`test`},
+				DependencyFilterFields:  []string{"id", "purl"},
+				StrictDependencyFilters: true,
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls).To(BeEmpty())
+			Expect(entryWriter.Calls).To(HaveLen(6))
+			Expect(entryWriter.Calls[1].Arguments[0]).To(Equal("testdata/test-sha256-1.toml"))
+			Expect(entryWriter.Calls[3].Arguments[0]).To(Equal("testdata/test-sha256-2.toml"))
+		})
+
+		it("fails with unknown filter field", func() {
+			carton.Package{
+				Source:                 path,
+				Destination:            "test-destination",
+				IncludeDependencies:    true,
+				CacheLocation:          "testdata",
+				DependencyFilters:      []string{`test`},
+				DependencyFilterFields: []string{"name"},
+			}.Create(
+				carton.WithEntryWriter(entryWriter),
+				carton.WithExecutor(executor),
+				carton.WithExitHandler(exitHandler))
+
+			Expect(exitHandler.Calls[0].Arguments.Get(0)).To(MatchError(ContainSubstring("unknown dependency filter field name")))
+		})
+
 		it("includes filter by version and id", func() {
 			carton.Package{
 				Source:                  path,
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Signer signs the manifest of a package and returns a detached signature.
+type Signer func(manifest []byte) ([]byte, error)
+
+// NewKeySigner creates a Signer that signs with key. Ed25519 keys sign the manifest itself, all other keys sign its
+// SHA256 digest.
+func NewKeySigner(key crypto.Signer) Signer {
+	return func(manifest []byte) ([]byte, error) {
+		if _, ok := key.(ed25519.PrivateKey); ok {
+			return key.Sign(rand.Reader, manifest, crypto.Hash(0))
+		}
+
+		digest := sha256.Sum256(manifest)
+		return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+}
+
+// ParseSigningKey parses a PEM encoded PKCS #8 private key that can be used to sign packages.
+func ParseSigningKey(in []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(in)
+	if block == nil {
+		return nil, fmt.Errorf("unable to decode signing key: no PEM block found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse signing key\n%w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unable to sign with key of type %T", key)
+	}
+
+	return signer, nil
+}
+
+type packageEntry struct {
+	path   string
+	source string
+}
+
+// packageManifest returns the manifest of entries, one "<sha256>  <path>" line per entry sorted by path.
+func packageManifest(entries []packageEntry) ([]byte, error) {
+	sorted := make([]packageEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].path < sorted[j].path
+	})
+
+	b := &bytes.Buffer{}
+	for _, e := range sorted {
+		in, err := os.Open(e.source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open %s\n%w", e.source, err)
+		}
+
+		s := sha256.New()
+		_, err = io.Copy(s, in)
+		in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash %s\n%w", e.source, err)
+		}
+
+		_, _ = fmt.Fprintf(b, "%x  %s\n", s.Sum(nil), filepath.ToSlash(e.path))
+	}
+
+	return b.Bytes(), nil
+}
+
+// writeSignature writes the manifest of entries to <destination>.manifest and its signature to <destination>.sig.
+func writeSignature(signer Signer, destination string, entries []packageEntry) error {
+	manifest, err := packageManifest(entries)
+	if err != nil {
+		return fmt.Errorf("unable to create package manifest\n%w", err)
+	}
+
+	signature, err := signer(manifest)
+	if err != nil {
+		return fmt.Errorf("unable to sign package manifest\n%w", err)
+	}
+
+	destination = filepath.Clean(destination)
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(destination), err)
+	}
+
+	file := fmt.Sprintf("%s.manifest", destination)
+	if err := os.WriteFile(file, manifest, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	file = fmt.Sprintf("%s.sig", destination)
+	if err := os.WriteFile(file, signature, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/carton"
+)
+
+func testSignature(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	encode := func(key interface{}) []byte {
+		b, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+	}
+
+	it("signs with an Ed25519 key", func() {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := carton.ParseSigningKey(encode(private))
+		Expect(err).NotTo(HaveOccurred())
+
+		signature, err := carton.NewKeySigner(key)([]byte("test-manifest"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ed25519.Verify(public, []byte("test-manifest"), signature)).To(BeTrue())
+	})
+
+	it("signs the digest with an ECDSA key", func() {
+		private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := carton.ParseSigningKey(encode(private))
+		Expect(err).NotTo(HaveOccurred())
+
+		signature, err := carton.NewKeySigner(key)([]byte("test-manifest"))
+		Expect(err).NotTo(HaveOccurred())
+
+		digest := sha256.Sum256([]byte("test-manifest"))
+		Expect(ecdsa.VerifyASN1(&private.PublicKey, digest[:], signature)).To(BeTrue())
+	})
+
+	it("fails without a PEM block", func() {
+		_, err := carton.ParseSigningKey([]byte("test-key"))
+		Expect(err).To(MatchError(ContainSubstring("no PEM block found")))
+	})
+}
@@ -0,0 +1,379 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package carton
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlDocument is a TOML document that is edited line by line, so that comments and formatting, including any byte
+// order mark and CRLF line endings, are preserved everywhere other than the values that are set.
+type tomlDocument struct {
+	bom   bool
+	lines []string
+}
+
+// tomlTable is the location of an element of an array of tables, such as [[metadata.dependencies]], in a tomlDocument.
+type tomlTable struct {
+	// start is the line of the table header.
+	start int
+
+	// own is the end (exclusive) of the table's own keys, which is the first of its sub-tables if it has any.
+	own int
+
+	// end is the end (exclusive) of the table, including its sub-tables.
+	end int
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+const utf8BOM = "\xEF\xBB\xBF"
+
+// newTOMLDocument returns a document for content.  Lines are split on \n, so lines ending with CRLF keep their \r.
+func newTOMLDocument(content []byte) *tomlDocument {
+	s := string(content)
+	bom := strings.HasPrefix(s, utf8BOM)
+
+	return &tomlDocument{bom: bom, lines: strings.Split(strings.TrimPrefix(s, utf8BOM), "\n")}
+}
+
+// Bytes returns the content of the document.
+func (d *tomlDocument) Bytes() []byte {
+	s := strings.Join(d.lines, "\n")
+	if d.bom {
+		s = utf8BOM + s
+	}
+
+	return []byte(s)
+}
+
+// Set sets key to value, which must already be TOML encoded, in the index'th element of the array of tables name.  An
+// existing value is replaced in place, keeping any comment that follows it, otherwise the key is added after the last
+// of the table's own keys.
+func (d *tomlDocument) Set(name string, index int, key string, value string) error {
+	tables := d.tables(name)
+	if index >= len(tables) {
+		return fmt.Errorf("unable to find [[%s]] table %d", name, index)
+	}
+	t := tables[index]
+
+	// remove sub-tables that declared a previous value, e.g. [[metadata.dependencies.licenses]]
+	prefix := fmt.Sprintf("%s.%s", name, key)
+	headers := d.headers()
+	for i := len(headers) - 1; i >= 0; i-- {
+		h := headers[i]
+		if h <= t.start || h >= t.end {
+			continue
+		}
+
+		if n, _ := headerName(d.lines[h]); n == prefix || strings.HasPrefix(n, prefix+".") {
+			end := t.end
+			if i+1 < len(headers) && headers[i+1] < end {
+				end = headers[i+1]
+			}
+			d.lines = append(d.lines[:h], d.lines[end:]...)
+			t.end -= end - h
+		}
+	}
+
+	last := t.start
+	for i := t.start + 1; i < t.own; i++ {
+		trimmed := strings.TrimSpace(d.lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		end := d.valueEnd(i)
+		if k, ok := keyName(d.lines[i]); ok && k == key {
+			line, cr := trimCR(d.lines[i])
+			e := strings.Index(line, "=") + 1
+			for e < len(line) && (line[e] == ' ' || line[e] == '\t') {
+				e++
+			}
+
+			var comment string
+			if end == i {
+				comment = trailingComment(line, e)
+			} else {
+				_, cr = trimCR(d.lines[end])
+			}
+
+			d.splice(i, end+1, line[:e]+value+comment+cr)
+			return nil
+		}
+
+		i, last = end, end
+	}
+
+	indent := ""
+	line, cr := trimCR(d.lines[last])
+	if last > t.start {
+		indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	}
+	d.splice(last+1, last+1, fmt.Sprintf("%s%s = %s%s", indent, encodeTOMLKey(key), value, cr))
+
+	return nil
+}
+
+func (d *tomlDocument) splice(start int, end int, lines ...string) {
+	d.lines = append(d.lines[:start], append(lines, d.lines[end:]...)...)
+}
+
+// headers returns the lines of every table header in the document.
+func (d *tomlDocument) headers() []int {
+	var headers []int
+
+	for i := 0; i < len(d.lines); i++ {
+		trimmed := strings.TrimSpace(d.lines[i])
+
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			headers = append(headers, i)
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		default:
+			i = d.valueEnd(i)
+		}
+	}
+
+	return headers
+}
+
+// tables returns the location of every element of the array of tables name.
+func (d *tomlDocument) tables(name string) []tomlTable {
+	var (
+		headers = d.headers()
+		tables  []tomlTable
+	)
+
+	for i, h := range headers {
+		if n, array := headerName(d.lines[h]); !array || n != name {
+			continue
+		}
+
+		t := tomlTable{start: h, own: len(d.lines), end: len(d.lines)}
+		for _, s := range headers[i+1:] {
+			n, _ := headerName(d.lines[s])
+			if !strings.HasPrefix(n, name+".") {
+				t.end = s
+				break
+			}
+			if t.own > s {
+				t.own = s
+			}
+		}
+		if t.own > t.end {
+			t.own = t.end
+		}
+
+		tables = append(tables, t)
+	}
+
+	return tables
+}
+
+// valueEnd returns the last line of the key/value that starts on line i, which is after i if the value is a multi-line
+// array, inline table, or string.
+func (d *tomlDocument) valueEnd(i int) int {
+	var (
+		depth int
+		quote string
+	)
+
+	for j := i; j < len(d.lines); j++ {
+		line := d.lines[j]
+		if j == i {
+			line = line[strings.Index(line, "=")+1:]
+		}
+
+		for k := 0; k < len(line); k++ {
+			c := line[k]
+
+			switch {
+			case quote == `"""` || quote == `'''`:
+				if strings.HasPrefix(line[k:], quote) {
+					quote, k = "", k+2
+				} else if quote == `"""` && c == '\\' {
+					k++
+				}
+			case quote != "":
+				if quote == `"` && c == '\\' {
+					k++
+				} else if c == quote[0] {
+					quote = ""
+				}
+			case strings.HasPrefix(line[k:], `"""`) || strings.HasPrefix(line[k:], `'''`):
+				quote, k = line[k:k+3], k+2
+			case c == '"' || c == '\'':
+				quote = string(c)
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+			case c == '#':
+				k = len(line)
+			}
+		}
+
+		if quote == `"` || quote == `'` {
+			quote = ""
+		}
+
+		if depth <= 0 && quote == "" {
+			return j
+		}
+	}
+
+	return len(d.lines) - 1
+}
+
+// trimCR returns line without a trailing \r and the \r that was removed, if any.
+func trimCR(line string) (string, string) {
+	if strings.HasSuffix(line, "\r") {
+		return strings.TrimSuffix(line, "\r"), "\r"
+	}
+
+	return line, ""
+}
+
+// headerName returns the dotted name of a table header and whether it is an array of tables.
+func headerName(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if i := strings.LastIndex(trimmed, "]"); i >= 0 {
+		trimmed = trimmed[:i+1]
+	}
+
+	array := strings.HasPrefix(trimmed, "[[") && strings.HasSuffix(trimmed, "]]")
+	trimmed = strings.Trim(trimmed, "[]")
+
+	parts := strings.Split(trimmed, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+
+	return strings.Join(parts, "."), array
+}
+
+// keyName returns the key of a key/value line.
+func keyName(line string) (string, bool) {
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return "", false
+	}
+
+	key := strings.TrimSpace(line[:i])
+	if u, err := strconv.Unquote(key); err == nil {
+		return u, true
+	}
+
+	return strings.Trim(key, "'"), true
+}
+
+// trailingComment returns the comment, including any whitespace before it, that follows the value starting at index
+// start of line.
+func trailingComment(line string, start int) string {
+	var quote byte
+
+	for k := start; k < len(line); k++ {
+		c := line[k]
+
+		switch {
+		case quote != 0:
+			if quote == '"' && c == '\\' {
+				k++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[len(strings.TrimRight(line[:k], " \t")):]
+		}
+	}
+
+	return ""
+}
+
+func encodeTOMLKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
+
+// encodeTOMLValue returns value encoded as a single line of TOML.  Tables are encoded as inline tables.
+func encodeTOMLValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var entries []string
+		for _, k := range keys {
+			e, err := encodeTOMLValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, fmt.Sprintf("%s = %s", encodeTOMLKey(k), e))
+		}
+
+		if len(entries) == 0 {
+			return "{}", nil
+		}
+		return fmt.Sprintf("{ %s }", strings.Join(entries, ", ")), nil
+
+	case []map[string]interface{}:
+		s := make([]interface{}, len(v))
+		for i := range v {
+			s[i] = v[i]
+		}
+		return encodeTOMLValue(s)
+
+	case []interface{}:
+		var elements []string
+		for _, e := range v {
+			s, err := encodeTOMLValue(e)
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, s)
+		}
+		return fmt.Sprintf("[%s]", strings.Join(elements, ", ")), nil
+
+	default:
+		b, err := toml.Marshal(map[string]interface{}{"value": v})
+		if err != nil {
+			return "", fmt.Errorf("unable to encode %v\n%w", v, err)
+		}
+
+		s := strings.TrimSpace(string(b))
+		if !strings.HasPrefix(s, "value = ") {
+			return "", fmt.Errorf("unable to encode %v as a single line", v)
+		}
+		return strings.TrimPrefix(s, "value = "), nil
+	}
+}
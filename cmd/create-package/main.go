@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -27,6 +28,11 @@ import (
 )
 
 func main() {
+	var (
+		signingKey      string
+		sourceDateEpoch int64
+	)
+
 	p := carton.Package{}
 
 	flagSet := pflag.NewFlagSet("Create Package", pflag.ExitOnError)
@@ -34,10 +40,18 @@ func main() {
 	flagSet.StringVar(&p.Destination, "destination", "", "path to the build package destination directory")
 	flagSet.BoolVar(&p.IncludeDependencies, "include-dependencies", false, "whether to include dependencies (default: false)")
 	flagSet.StringSliceVar(&p.DependencyFilters, "dependency-filter", []string{}, "one or more filters that are applied to exclude dependencies")
+	flagSet.StringSliceVar(&p.DependencyFilterFields, "dependency-filter-fields", []string{}, "fields of a dependency the filters match against: id, version, purl, and arch (default: id,version)")
 	flagSet.BoolVar(&p.StrictDependencyFilters, "strict-filters", false, "require filter to match all data or just some data (default: false)")
+	flagSet.BoolVar(&p.StrictChecksums, "strict-checksums", false, "fail if a dependency has no sha256 (default: false)")
 	flagSet.StringVar(&p.Source, "source", defaultSource(), "path to build package source directory (default: $PWD)")
 	flagSet.StringVar(&p.Version, "version", "", "version to substitute into buildpack.toml")
-	flagSet.StringVar(&p.TargetArch, "target-arch", carton.DefaultTargetArch, "target architecture for the package (default: all)")
+	flagSet.StringVar(&p.TargetArch, "target-arch", carton.DefaultTargetArch, "target architecture for the package, or a comma separated list of architectures (default: all)")
+	flagSet.StringVar(&p.Format, "format", carton.DirectoryFormat, "format of the package, dir or oci (default: dir)")
+	flagSet.BoolVar(&p.DryRun, "dry-run", false, "log the entries of the package without writing them (default: false)")
+	flagSet.StringVar(&p.DependenciesFile, "dependencies-file", "", "path to a TOML or JSON file of dependencies to merge with those in buildpack.toml")
+	flagSet.StringVar(&p.EmitManifest, "emit-manifest", "", "path to write a JSON manifest of the packaged entries to")
+	flagSet.StringVar(&signingKey, "signing-key", "", "path to a PEM encoded PKCS #8 private key to sign the package manifest with")
+	flagSet.Int64Var(&sourceDateEpoch, "source-date-epoch", 0, "seconds since the Unix epoch to set as the modification time of every entry (default: $SOURCE_DATE_EPOCH)")
 
 	if err := flagSet.Parse(os.Args[1:]); err != nil {
 		log.Fatal(fmt.Errorf("unable to parse flags\n%w", err))
@@ -47,7 +61,26 @@ func main() {
 		log.Fatal("destination must be set")
 	}
 
-	p.Create()
+	if sourceDateEpoch != 0 {
+		p.ModificationTime = time.Unix(sourceDateEpoch, 0)
+	}
+
+	var options []carton.Option
+	if signingKey != "" {
+		b, err := os.ReadFile(signingKey)
+		if err != nil {
+			log.Fatal(fmt.Errorf("unable to read %s\n%w", signingKey, err))
+		}
+
+		key, err := carton.ParseSigningKey(b)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		options = append(options, carton.WithSigner(carton.NewKeySigner(key)))
+	}
+
+	p.Create(options...)
 }
 
 func defaultSource() string {
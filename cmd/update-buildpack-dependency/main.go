@@ -37,6 +37,8 @@ func main() {
 	flagSet.StringVar(&b.URI, "uri", "", "the new uri of the dependency")
 	flagSet.StringVar(&b.Version, "version", "", "the new version of the dependency")
 	flagSet.StringVar(&b.VersionPattern, "version-pattern", "", "the version pattern of the dependency")
+	flagSet.BoolVar(&b.VersionConstraint, "version-constraint", false, "match version-pattern as a semver constraint, such as 17.*, instead of a regular expression")
+	flagSet.StringVar(&b.MatchPURL, "match-purl", "", "the exact purl of the dependency to update, used instead of id, arch and version-pattern")
 	flagSet.StringVar(&b.PURL, "purl", "", "the new purl version of the dependency, if not set defaults to version")
 	flagSet.StringVar(&b.PURLPattern, "purl-pattern", "", "the purl version pattern of the dependency, if not set defaults to version-pattern")
 	flagSet.StringVar(&b.CPE, "cpe", "", "the new version use in all CPEs, if not set defaults to version")
@@ -44,6 +46,8 @@ func main() {
 	flagSet.StringVar(&b.Source, "source", "", "the new uri of the dependency source")
 	flagSet.StringVar(&b.SourceSHA256, "source-sha256", "", "the new sha256 of the dependency source")
 	flagSet.StringVar(&b.EolID, "eol-id", "", "id of the dependency for looking up the EOL date on the https://endoflife.date/")
+	flagSet.StringVar(&b.DeprecationDate, "deprecation-date", "", "the new deprecation date of the dependency (RFC3339 or YYYY-MM-DD), takes precedence over eol-id")
+	fields := flagSet.StringToString("field", nil, "additional fields to set on the dependency as key=value, e.g. name=Some Name")
 
 	if err := flagSet.Parse(os.Args[1:]); err != nil {
 		log.Fatal(fmt.Errorf("unable to parse flags\n%w", err))
@@ -53,8 +57,8 @@ func main() {
 		log.Fatal("buildpack-toml must be set")
 	}
 
-	if b.ID == "" {
-		log.Fatal("id must be set")
+	if b.ID == "" && b.MatchPURL == "" {
+		log.Fatal("id or match-purl must be set")
 	}
 
 	if b.Arch == "" {
@@ -73,8 +77,8 @@ func main() {
 		log.Fatal("version must be set")
 	}
 
-	if b.VersionPattern == "" {
-		log.Fatal("version-pattern must be set")
+	if b.VersionPattern == "" && b.MatchPURL == "" {
+		log.Fatal("version-pattern or match-purl must be set")
 	}
 
 	if b.PURL == "" {
@@ -93,5 +97,12 @@ func main() {
 		b.CPEPattern = b.VersionPattern
 	}
 
+	if len(*fields) > 0 {
+		b.Fields = map[string]interface{}{}
+		for k, v := range *fields {
+			b.Fields[k] = v
+		}
+	}
+
 	b.Update()
 }
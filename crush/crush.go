@@ -22,14 +22,18 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/h2non/filetype"
 	"github.com/xi2/xz"
+
+	"github.com/paketo-buildpacks/libpak/bard"
 )
 
 // CreateTar writes a TAR to the destination io.Writer containing the directories and files in the source folder.
@@ -97,7 +101,17 @@ func CreateTar(destination io.Writer, source string) error {
 // CreateTarGz writes a GZIP'd TAR to the destination io.Writer containing the directories and files in the source
 // folder.
 func CreateTarGz(destination io.Writer, source string) error {
-	gz := gzip.NewWriter(destination)
+	return CreateTarGzLevel(destination, source, gzip.DefaultCompression)
+}
+
+// CreateTarGzLevel writes a GZIP'd TAR to the destination io.Writer containing the directories and files in the source
+// folder, compressed at the given level.  The level must be gzip.HuffmanOnly, gzip.DefaultCompression, or between
+// gzip.NoCompression and gzip.BestCompression.
+func CreateTarGzLevel(destination io.Writer, source string, level int) error {
+	gz, err := gzip.NewWriterLevel(destination, level)
+	if err != nil {
+		return fmt.Errorf("unable to create GZIP writer with level %d\n%w", level, err)
+	}
 	defer gz.Close()
 
 	return CreateTar(gz, source)
@@ -185,7 +199,24 @@ func CreateJar(source, target string) error {
 
 // Extract decompresses and extract source files to a destination directory or path. For archives, an arbitrary number of top-level directory
 // components can be stripped from each path.
+// Concatenated GZIP members and multi-stream XZ files are decompressed in full.
 func Extract(source io.Reader, destination string, stripComponents int) error {
+	return extract(source, destination, stripComponents, nil)
+}
+
+// ExtractWithOwner decompresses and extracts source as Extract does, and changes the owner of every file, directory,
+// and symlink it creates to uid and gid.  If the owner cannot be changed because it is not permitted, for example
+// when not running as root, ownership is left unchanged and the reason is logged at debug level.
+func ExtractWithOwner(source io.Reader, destination string, stripComponents int, uid int, gid int) error {
+	return extract(source, destination, stripComponents, &owner{
+		uid:         uid,
+		gid:         gid,
+		destination: filepath.Clean(destination),
+		changed:     map[string]bool{},
+	})
+}
+
+func extract(source io.Reader, destination string, stripComponents int, o *owner) error {
 	buf := &bytes.Buffer{}
 
 	kind, err := filetype.MatchReader(io.TeeReader(source, buf))
@@ -197,24 +228,26 @@ func Extract(source io.Reader, destination string, stripComponents int) error {
 
 	switch kind.MIME.Value {
 	case "application/x-tar":
-		return extractTar(source, destination, stripComponents)
+		return extractTar(source, destination, stripComponents, o)
 	case "application/zip":
-		return extractZip(source, destination, stripComponents)
+		return extractZip(source, destination, stripComponents, o)
 	case "application/x-bzip2":
-		return Extract(bzip2.NewReader(source), destination, stripComponents)
+		return extract(bzip2.NewReader(source), destination, stripComponents, o)
 	case "application/gzip":
 		gz, err := gzip.NewReader(source)
 		if err != nil {
 			return fmt.Errorf("unable to create GZIP reader\n%w", err)
 		}
 		defer gz.Close()
-		return Extract(gz, destination, stripComponents)
+		gz.Multistream(true)
+		return extract(gz, destination, stripComponents, o)
 	case "application/x-xz":
 		xz, err := xz.NewReader(source, 0)
 		if err != nil {
 			return fmt.Errorf("unable to create XZ reader\n%w", err)
 		}
-		return Extract(xz, destination, stripComponents)
+		xz.Multistream(true)
+		return extract(xz, destination, stripComponents, o)
 	default:
 		// no archive, can happen with xz/gzip/bz2 if compressed file is not an archive
 		in, err := os.Create(destination)
@@ -228,6 +261,146 @@ func Extract(source io.Reader, destination string, stripComponents int) error {
 		}
 	}
 
+	return o.chown(destination)
+}
+
+// ExtractStripTop decompresses and extracts a source archive to a destination directory.  If every path in the archive
+// shares a single top-level directory, that directory is stripped and its name is returned.  Otherwise nothing is
+// stripped and the returned name is empty.
+func ExtractStripTop(source io.Reader, destination string) (string, error) {
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return "", fmt.Errorf("unable to make directory %s\n%w", destination, err)
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(destination), ".crush-*")
+	if err != nil {
+		return "", fmt.Errorf("unable to create staging directory\n%w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := Extract(source, staging, 0); err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s\n%w", staging, err)
+	}
+
+	root, top := staging, ""
+	if len(entries) == 1 && entries[0].IsDir() {
+		top = entries[0].Name()
+		root = filepath.Join(staging, top)
+	}
+
+	if err := moveContents(root, destination); err != nil {
+		return "", err
+	}
+
+	return top, nil
+}
+
+// Verify reads source, detecting its format as Extract does, and returns an error if it is truncated or malformed.
+// Every TAR and ZIP entry is read, and ZIP entries are checked against their CRC, but nothing is written.
+func Verify(source io.Reader) error {
+	buf := &bytes.Buffer{}
+
+	kind, err := filetype.MatchReader(io.TeeReader(source, buf))
+	if err != nil {
+		return err
+	}
+
+	source = io.MultiReader(buf, source)
+
+	switch kind.MIME.Value {
+	case "application/x-tar":
+		return verifyTar(source)
+	case "application/zip":
+		return verifyZip(source)
+	case "application/x-bzip2":
+		return Verify(bzip2.NewReader(source))
+	case "application/gzip":
+		gz, err := gzip.NewReader(source)
+		if err != nil {
+			return fmt.Errorf("unable to create GZIP reader\n%w", err)
+		}
+		defer gz.Close()
+		gz.Multistream(true)
+		return Verify(gz)
+	case "application/x-xz":
+		xz, err := xz.NewReader(source, 0)
+		if err != nil {
+			return fmt.Errorf("unable to create XZ reader\n%w", err)
+		}
+		xz.Multistream(true)
+		return Verify(xz)
+	default:
+		// no archive, can happen with xz/gzip/bz2 if compressed file is not an archive
+		if _, err := io.Copy(io.Discard, source); err != nil {
+			return fmt.Errorf("unable to read source\n%w", err)
+		}
+	}
+
+	return nil
+}
+
+func verifyTar(source io.Reader) error {
+	t := tar.NewReader(source)
+
+	for {
+		f, err := t.Next()
+		if err != nil && err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to read TAR file\n%w", err)
+		}
+
+		if _, err := io.Copy(io.Discard, t); err != nil {
+			return fmt.Errorf("unable to read TAR entry %s\n%w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func verifyZip(source io.Reader) error {
+	buffer, err := os.CreateTemp("", "")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(buffer.Name())
+	defer buffer.Close()
+
+	size, err := io.Copy(buffer, source)
+	if err != nil {
+		return err
+	}
+
+	z, err := zip.NewReader(buffer, size)
+	if err != nil {
+		return fmt.Errorf("unable to read ZIP file\n%w", err)
+	}
+
+	for _, f := range z.File {
+		if err := verifyZipEntry(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyZipEntry(file *zip.File) error {
+	in, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("unable to open ZIP entry %s\n%w", file.Name, err)
+	}
+	defer in.Close()
+
+	if _, err := io.Copy(io.Discard, in); err != nil {
+		return fmt.Errorf("unable to read ZIP entry %s\n%w", file.Name, err)
+	}
+
 	return nil
 }
 
@@ -236,10 +409,10 @@ func Extract(source io.Reader, destination string, stripComponents int) error {
 //
 // Deprecated: use Extract instead
 func ExtractTar(source io.Reader, destination string, stripComponents int) error {
-	return extractTar(source, destination, stripComponents)
+	return extractTar(source, destination, stripComponents, nil)
 }
 
-func extractTar(source io.Reader, destination string, stripComponents int) error {
+func extractTar(source io.Reader, destination string, stripComponents int, o *owner) error {
 	t := tar.NewReader(source)
 
 	for {
@@ -269,6 +442,10 @@ func extractTar(source io.Reader, destination string, stripComponents int) error
 				return err
 			}
 		}
+
+		if err := o.chown(target); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -292,6 +469,7 @@ func ExtractTarGz(source io.Reader, destination string, stripComponents int) err
 		return fmt.Errorf("unable to create GZIP reader\n%w", err)
 	}
 	defer gz.Close()
+	gz.Multistream(true)
 
 	return ExtractTar(gz, destination, stripComponents)
 }
@@ -305,6 +483,7 @@ func ExtractTarXz(source io.Reader, destination string, stripComponents int) err
 	if err != nil {
 		return fmt.Errorf("unable to create XZ reader\n%w", err)
 	}
+	xz.Multistream(true)
 
 	return ExtractTar(xz, destination, stripComponents)
 }
@@ -314,10 +493,10 @@ func ExtractTarXz(source io.Reader, destination string, stripComponents int) err
 //
 // Deprecated: use Extract instead
 func ExtractZip(source io.Reader, destination string, stripComponents int) error {
-	return extractZip(source, destination, stripComponents)
+	return extractZip(source, destination, stripComponents, nil)
 }
 
-func extractZip(source io.Reader, destination string, stripComponents int) error {
+func extractZip(source io.Reader, destination string, stripComponents int, o *owner) error {
 	buffer, err := os.CreateTemp("", "")
 	if err != nil {
 		return err
@@ -349,6 +528,47 @@ func extractZip(source io.Reader, destination string, stripComponents int) error
 				return err
 			}
 		}
+
+		if err := o.chown(target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// owner changes the owner of extracted paths.  A nil owner leaves ownership unchanged.
+type owner struct {
+	uid         int
+	gid         int
+	destination string
+	changed     map[string]bool
+	disabled    bool
+}
+
+// chown changes the owner of path, and of any of its parent directories up to and including the destination, that
+// have not already been changed.  If changing the owner is not permitted, all further changes are skipped.
+func (o *owner) chown(path string) error {
+	if o == nil || o.disabled {
+		return nil
+	}
+
+	for p := filepath.Clean(path); !o.changed[p]; p = filepath.Dir(p) {
+		if p != o.destination && !strings.HasPrefix(p, o.destination+string(filepath.Separator)) {
+			break
+		}
+
+		if err := os.Lchown(p, o.uid, o.gid); err != nil {
+			if errors.Is(err, fs.ErrPermission) || errors.Is(err, errors.ErrUnsupported) {
+				bard.NewLogger(os.Stdout).Debugf("unable to change owner of %s to %d:%d, leaving ownership unchanged\n%s",
+					p, o.uid, o.gid, err)
+				o.disabled = true
+				return nil
+			}
+
+			return fmt.Errorf("unable to change owner of %s\n%w", p, err)
+		}
+		o.changed[p] = true
 	}
 
 	return nil
@@ -364,6 +584,36 @@ func strippedPath(source string, destination string, stripComponents int) string
 	return filepath.Join(append([]string{destination}, components[stripComponents:]...)...)
 }
 
+// moveContents moves everything in source into destination, merging directories that already exist.
+func moveContents(source string, destination string) error {
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", source, err)
+	}
+
+	for _, e := range entries {
+		from := filepath.Join(source, e.Name())
+		to := filepath.Join(destination, e.Name())
+
+		if info, err := os.Lstat(to); err == nil && info.IsDir() && e.IsDir() {
+			if err := moveContents(from, to); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.RemoveAll(to); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", to, err)
+		}
+
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("unable to move %s to %s\n%w", from, to, err)
+		}
+	}
+
+	return nil
+}
+
 func writeFile(source io.Reader, path string, perm os.FileMode) error {
 	file := filepath.Dir(path)
 	if err := os.MkdirAll(file, 0755); err != nil {
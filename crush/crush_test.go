@@ -17,8 +17,14 @@
 package crush_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -96,6 +102,22 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 			Expect(os.Readlink(filepath.Join(testPath, "dirA", "fileD.txt"))).To(Equal(filepath.Join(path, "dirA", "fileC.txt")))
 		})
 
+		it("writes a TAR.GZ with compression level", func() {
+			Expect(os.WriteFile(filepath.Join(path, "fileA.txt"), []byte(""), 0644)).To(Succeed())
+
+			Expect(crush.CreateTarGzLevel(out, path, gzip.BestCompression)).To(Succeed())
+
+			in, err := os.Open(out.Name())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(crush.ExtractTarGz(in, testPath, 0)).To(Succeed())
+			Expect(filepath.Join(testPath, "fileA.txt")).To(BeARegularFile())
+		})
+
+		it("fails with invalid compression level", func() {
+			Expect(crush.CreateTarGzLevel(out, path, 42)).To(MatchError(ContainSubstring("unable to create GZIP writer with level 42")))
+		})
+
 		it("writes a JAR", func() {
 			cwd, _ := os.Getwd()
 			Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
@@ -282,6 +304,21 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("TarGZ concatenated members", func() {
+				it.Before(func() {
+					var err error
+					in, err = os.Open(filepath.Join("testdata", "test-archive-multistream.tar.gz"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("extracts the whole archive", func() {
+					Expect(crush.Extract(in, path, 0)).To(Succeed())
+					Expect(filepath.Join(path, "fileA.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileB.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileC.txt")).To(BeARegularFile())
+				})
+			})
+
 			context("TarBz2", func() {
 				it.Before(func() {
 					var err error
@@ -324,6 +361,21 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("TarXZ multiple streams", func() {
+				it.Before(func() {
+					var err error
+					in, err = os.Open(filepath.Join("testdata", "test-archive-multistream.tar.xz"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("extracts the whole archive", func() {
+					Expect(crush.Extract(in, path, 0)).To(Succeed())
+					Expect(filepath.Join(path, "fileA.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileB.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileC.txt")).To(BeARegularFile())
+				})
+			})
+
 			context("Zip", func() {
 				it.Before(func() {
 					var err error
@@ -366,6 +418,89 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
+			context("ExtractStripTop", func() {
+				it("strips a single top-level directory", func() {
+					source := t.TempDir()
+					Expect(os.MkdirAll(filepath.Join(source, "test-top", "dirA"), 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(source, "test-top", "fileA.txt"), []byte{}, 0644)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(source, "test-top", "dirA", "fileB.txt"), []byte{}, 0644)).To(Succeed())
+
+					var err error
+					in, err = os.Create(filepath.Join(t.TempDir(), "test-archive.tar.gz"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(crush.CreateTarGz(in, source)).To(Succeed())
+					_, err = in.Seek(0, io.SeekStart)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(crush.ExtractStripTop(in, path)).To(Equal("test-top"))
+					Expect(filepath.Join(path, "fileA.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileB.txt")).To(BeARegularFile())
+				})
+
+				it("strips nothing with multiple top-level entries", func() {
+					var err error
+					in, err = os.Open(filepath.Join("testdata", "test-archive.tar.gz"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(crush.ExtractStripTop(in, path)).To(BeEmpty())
+					Expect(filepath.Join(path, "fileA.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileB.txt")).To(BeARegularFile())
+					Expect(filepath.Join(path, "dirA", "fileC.txt")).To(BeARegularFile())
+				})
+			})
+
+			context("ExtractWithOwner", func() {
+				owner := func(path string) (uint64, uint64) {
+					info, err := os.Lstat(path)
+					Expect(err).NotTo(HaveOccurred())
+
+					stat := reflect.Indirect(reflect.ValueOf(info.Sys()))
+					return stat.FieldByName("Uid").Uint(), stat.FieldByName("Gid").Uint()
+				}
+
+				it.Before(func() {
+					if runtime.GOOS == "windows" {
+						t.Skip("ownership is not supported on windows")
+					}
+
+					source := t.TempDir()
+					Expect(os.MkdirAll(filepath.Join(source, "dirA"), 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(source, "dirA", "fileB.txt"), []byte{}, 0644)).To(Succeed())
+					Expect(os.Symlink("fileB.txt", filepath.Join(source, "dirA", "linkB.txt"))).To(Succeed())
+
+					var err error
+					in, err = os.Create(filepath.Join(t.TempDir(), "test-archive.tar.gz"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(crush.CreateTarGz(in, source)).To(Succeed())
+					_, err = in.Seek(0, io.SeekStart)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("changes the owner of extracted files", func() {
+					destination := filepath.Join(path, "test-destination")
+					Expect(crush.ExtractWithOwner(in, destination, 0, 1234, 5678)).To(Succeed())
+
+					uid, gid := uint64(os.Getuid()), uint64(os.Getgid())
+					if os.Getuid() == 0 {
+						uid, gid = 1234, 5678
+					}
+
+					for _, p := range []string{
+						destination,
+						filepath.Join(destination, "dirA"),
+						filepath.Join(destination, "dirA", "fileB.txt"),
+						filepath.Join(destination, "dirA", "linkB.txt"),
+					} {
+						u, g := owner(p)
+						Expect(u).To(Equal(uid), p)
+						Expect(g).To(Equal(gid), p)
+					}
+
+					u, _ := owner(path)
+					Expect(u).To(Equal(uint64(os.Getuid())))
+				})
+			})
+
 			context("compression only", func() {
 				it("decompresses gzip", func() {
 					var err error
@@ -385,6 +520,15 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 					Expect(filepath.Join(path, "test-compress")).To(BeARegularFile())
 				})
 
+				it("decompresses concatenated gzip", func() {
+					var err error
+					in, err = os.Open(filepath.Join("testdata", "test-compress-multistream.gz"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(crush.Extract(in, filepath.Join(path, "test-compress"), 0)).To(Succeed())
+					Expect(os.ReadFile(filepath.Join(path, "test-compress"))).To(Equal([]byte("test-compress")))
+				})
+
 				it("decompresses bz2", func() {
 					var err error
 					in, err = os.Open(filepath.Join("testdata", "test-compress.bz2"))
@@ -396,4 +540,45 @@ func testCrush(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("Verify", func() {
+		var (
+			Expect = NewWithT(t).Expect
+		)
+
+		for _, name := range []string{"test-archive.tar", "test-archive.tar.bz2", "test-archive.tar.gz",
+			"test-archive.tar.xz", "test-archive.zip", "test-compress.gz"} {
+			name := name
+
+			it(fmt.Sprintf("verifies %s", name), func() {
+				b, err := os.ReadFile(filepath.Join("testdata", name))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(crush.Verify(bytes.NewReader(b))).To(Succeed())
+			})
+		}
+
+		it("fails with truncated tar.gz", func() {
+			b, err := os.ReadFile(filepath.Join("testdata", "test-archive.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(crush.Verify(bytes.NewReader(b[:len(b)-16]))).NotTo(Succeed())
+		})
+
+		it("fails with truncated zip", func() {
+			b, err := os.ReadFile(filepath.Join("testdata", "test-archive.zip"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(crush.Verify(bytes.NewReader(b[:len(b)/2]))).NotTo(Succeed())
+		})
+
+		it("does not write anything", func() {
+			in, err := os.Open(filepath.Join("testdata", "test-archive.tar"))
+			Expect(err).NotTo(HaveOccurred())
+			defer in.Close()
+
+			Expect(crush.Verify(in)).To(Succeed())
+			Expect(os.ReadDir(path)).To(BeEmpty())
+		})
+	})
 }
@@ -17,18 +17,31 @@
 package libpak
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/md5"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -47,6 +60,34 @@ type HttpClientTimeouts struct {
 	ExpectContinueTimeout time.Duration
 }
 
+//go:generate mockery -name ArtifactResolver -case=underscore
+
+// ArtifactResolver is the interface for types that return the artifact of a BuildpackDependency, such as
+// DependencyCache.  It allows a mock to be used in place of a DependencyCache, for example in a
+// DependencyLayerContributor.
+type ArtifactResolver interface {
+
+	// Artifact returns the artifact of the dependency.
+	Artifact(dependency BuildpackDependency, mods ...RequestModifierFunc) (*os.File, error)
+}
+
+//go:generate mockery -name DependencyCacheObserver -case=underscore
+
+// DependencyCacheObserver is the interface for types that observe the resolution of artifacts by a DependencyCache,
+// for example to record metrics.
+type DependencyCacheObserver interface {
+
+	// OnCacheHit is called when an artifact is found in either CachePath or DownloadPath.
+	OnCacheHit(dependency BuildpackDependency)
+
+	// OnCacheMiss is called when an artifact must be downloaded.
+	OnCacheMiss(dependency BuildpackDependency)
+
+	// OnDownloadComplete is called when an artifact has been downloaded and verified, with the number of bytes
+	// downloaded and the time taken.
+	OnDownloadComplete(dependency BuildpackDependency, bytes int64, duration time.Duration)
+}
+
 // DependencyCache allows a user to get an artifact either from a buildpack's cache, a previous download,
 // a mirror registry, or to download directly.
 type DependencyCache struct {
@@ -54,44 +95,118 @@ type DependencyCache struct {
 	// CachePath is the location where the buildpack has cached its dependencies.
 	CachePath string
 
+	// ConfigurationResolver resolves ${NAME} placeholders in dependency URIs from the environment, falling back to the
+	// defaults of the buildpack's configurations.  If nil, placeholders are resolved from the environment only.
+	ConfigurationResolver *ConfigurationResolver
+
 	// DownloadPath is the location of all downloads during this execution of the build.
 	DownloadPath string
 
 	// Logger is the logger used to write to the console.
 	Logger bard.Logger
 
+	// MaxArtifactBytes is the maximum size in bytes of an artifact that will be downloaded.  A download that is larger
+	// fails with an ArtifactTooLargeError, before it starts if the server reports the size of the response.  If zero,
+	// the size of artifacts is not limited.
+	MaxArtifactBytes int64
+
 	// UserAgent is the User-Agent string to use with requests.
 	UserAgent string
 
+	// AcceptEncodings are the content codings, in order of preference, that downloads may be compressed with.  They are
+	// sent as the Accept-Encoding header and a compressed response is decoded as it is downloaded, so the artifact is
+	// stored and verified in its decoded form.  Only "gzip", "deflate", and "identity" are supported.  If empty, the
+	// HTTP client negotiates gzip transparently.
+	AcceptEncodings []string
+
 	// Mappings optionally provides URIs mapping for BuildpackDependencies
 	Mappings map[string]string
 
 	// httpClientTimeouts contains the timeout values used by HTTP client
 	HttpClientTimeouts HttpClientTimeouts
 
-	// Alternative sources used for downloading dependencies.
+	// Alternative sources used for downloading dependencies.  A value may be a comma or whitespace separated list of
+	// mirrors, which are tried in order before falling back to the original URI.
 	DependencyMirrors map[string]string
+
+	// DependencyIDMirrors are alternative sources keyed by lower case dependency ID.  A mirror for the ID of a dependency
+	// takes precedence over any of the DependencyMirrors.
+	DependencyIDMirrors map[string]string
+
+	// DialerNetwork is the network used to connect when downloading dependencies: "tcp" for either IP family, "tcp4"
+	// for IPv4 only, or "tcp6" for IPv6 only.  If empty, "tcp" is used.
+	DialerNetwork string
+
+	// HTTPClient is the client used to download dependencies.  If nil, a client configured with HttpClientTimeouts is
+	// used.
+	HTTPClient *http.Client
+
+	// VerifyLocalhostTLS indicates whether TLS certificates should be verified when downloading from localhost or
+	// 127.0.0.1.  By default they are not, for compatibility with local test servers using self-signed certificates.
+	VerifyLocalhostTLS bool
+
+	// Observer is notified of cache hits, misses, and downloads.  If nil, nothing is notified.
+	Observer DependencyCacheObserver
+
+	// URIRewriter optionally rewrites each URI before it is downloaded, for example to route requests through a proxy.
+	// It is applied after Mappings and DependencyMirrors.
+	URIRewriter func(original *url.URL) (*url.URL, error)
+
+	// usage tracks the Mappings and DependencyMirrors that have been used, and is shared between copies of the
+	// DependencyCache.
+	usage *dependencyCacheUsage
+
+	// LinkDownloadsByID indicates whether each download should also be linked from DownloadPath/by-id/<ID>/<VERSION>
+	// to the DownloadPath/<SHA256> directory that contains it, so that a cache can be navigated by dependency.  The
+	// links are for navigation only, artifacts are always resolved by SHA256, and a failure to link is logged and
+	// otherwise ignored.
+	LinkDownloadsByID bool
+
+	// VerifyOnReuse indicates whether artifacts reused from CachePath should have their checksum verified again before
+	// they are returned, to detect files that have been corrupted on disk.  An artifact that does not match is logged
+	// and resolved from DownloadPath or downloaded instead.  By default, an artifact is trusted if its metadata matches.
+	VerifyOnReuse bool
+
+	// PromoteDownloads indicates whether verified downloads should also be copied into CachePath so that later builds
+	// reuse them rather than downloading again.  A failure to promote a download is logged and otherwise ignored.
+	PromoteDownloads bool
+
+	// ProbeOnValidate indicates whether Validate should also check that each of the Mappings and DependencyMirrors can
+	// be reached, with a HEAD request for https URIs and by checking that the path exists for file URIs.
+	ProbeOnValidate bool
 }
 
 // NewDependencyCache creates a new instance setting the default cache path (<BUILDPACK_PATH>/dependencies) and user
-// agent (<BUILDPACK_ID>/<BUILDPACK_VERSION>).
+// agent (<BUILDPACK_ID>/<BUILDPACK_VERSION>).  The cache path can be overridden with $BP_DEPENDENCY_CACHE_DIR, for
+// example to use a cache shared between buildpacks.  Downloads can be restricted to IPv4 or IPv6 by setting
+// $BP_DIALER_NETWORK to tcp4 or tcp6.  TLS certificates of localhost downloads are verified if
+// $BP_INSECURE_LOCALHOST is false.  The size of downloads can be limited with $BP_MAX_ARTIFACT_SIZE, in bytes, and an
+// error is returned if it is not a valid size.
 // Mappings will be read from any libcnb.Binding in the context with type "dependency-mappings".
 //
 // In some environments, many dependencies might need to be downloaded from a (local) mirror registry or filesystem.
 // Such alternative locations can be configured using bindings of type "dependency-mirror", avoiding too many "dependency-mapping" bindings.
 // Environment variables named "BP_DEPENDENCY_MIRROR" (default) or "BP_DEPENDENCY_MIRROR_<HOSTNAME>" (hostname-specific mirror)
-// can also be used for the same purpose.
+// can also be used for the same purpose.  A mirror for a single dependency can be set with an environment variable
+// named "BP_DEPENDENCY_ID_MIRROR_<DEPENDENCY_ID>", which takes precedence over any hostname mirror.
+//
+// A mirror may be followed by comma separated arguments.  "skip-path=<prefix>" removes a prefix from the original
+// path, and "preserve-path=true" places the original host and path under the mirror, so that
+// https://example.com/x/y.tgz is downloaded from <mirror>/example.com/x/y.tgz.  The same layout can be spelled out with
+// a {originalHost} placeholder in the mirror URI.
 func NewDependencyCache(context libcnb.BuildContext) (DependencyCache, error) {
 	cache := DependencyCache{
-		CachePath:         filepath.Join(context.Buildpack.Path, "dependencies"),
-		DownloadPath:      os.TempDir(),
-		UserAgent:         fmt.Sprintf("%s/%s", context.Buildpack.Info.ID, context.Buildpack.Info.Version),
-		Mappings:          map[string]string{},
-		DependencyMirrors: map[string]string{},
+		CachePath:           sherpa.GetEnvWithDefault("BP_DEPENDENCY_CACHE_DIR", filepath.Join(context.Buildpack.Path, "dependencies")),
+		DownloadPath:        os.TempDir(),
+		UserAgent:           fmt.Sprintf("%s/%s", context.Buildpack.Info.ID, context.Buildpack.Info.Version),
+		Mappings:            map[string]string{},
+		DependencyMirrors:   map[string]string{},
+		DependencyIDMirrors: map[string]string{},
 		// We create the logger here because the initialization process may log some warnings that should be visible to users.
 		// This goes against the usual pattern, which has the user supply the Logger after initialization.
 		// There's no choice though, if we want the warning messages to be visible to users. We should clean this up in v2.
 		Logger: bard.NewLogger(os.Stdout),
+		usage:  &dependencyCacheUsage{},
 	}
 	mappings, err := filterBindingsByType(context.Platform.Bindings, "dependency-mapping")
 	if err != nil {
@@ -99,11 +214,18 @@ func NewDependencyCache(context libcnb.BuildContext) (DependencyCache, error) {
 	}
 	cache.Mappings = mappings
 
-	clientTimeouts, err := customizeHttpClientTimeouts()
+	cache.HttpClientTimeouts = customizeHttpClientTimeouts()
+	cache.DialerNetwork = customizeDialerNetwork(cache.Logger)
+	cache.VerifyLocalhostTLS = !sherpa.GetEnvBoolWithDefault("BP_INSECURE_LOCALHOST", true)
+	if cache.MaxArtifactBytes, err = maxArtifactBytes(); err != nil {
+		return DependencyCache{}, err
+	}
+
+	cr, err := NewConfigurationResolver(context.Buildpack, nil)
 	if err != nil {
-		return DependencyCache{}, fmt.Errorf("unable to read custom timeout settings\n%w", err)
+		return DependencyCache{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 	}
-	cache.HttpClientTimeouts = *clientTimeouts
+	cache.ConfigurationResolver = &cr
 
 	bindingMirrors, err := filterBindingsByType(context.Platform.Bindings, "dependency-mirror")
 	if err != nil {
@@ -114,44 +236,46 @@ func NewDependencyCache(context libcnb.BuildContext) (DependencyCache, error) {
 	return cache, nil
 }
 
-func customizeHttpClientTimeouts() (*HttpClientTimeouts, error) {
-	rawStr := sherpa.GetEnvWithDefault("BP_DIALER_TIMEOUT", "6")
-	dialerTimeout, err := strconv.Atoi(rawStr)
-	if err != nil {
-		return nil, fmt.Errorf("unable to convert BP_DIALER_TIMEOUT=%s to integer\n%w", rawStr, err)
+func customizeHttpClientTimeouts() HttpClientTimeouts {
+	return HttpClientTimeouts{
+		DialerTimeout:         time.Duration(sherpa.GetEnvIntWithDefault("BP_DIALER_TIMEOUT", 6)) * time.Second,
+		DialerKeepAlive:       time.Duration(sherpa.GetEnvIntWithDefault("BP_DIALER_KEEP_ALIVE", 60)) * time.Second,
+		TLSHandshakeTimeout:   time.Duration(sherpa.GetEnvIntWithDefault("BP_TLS_HANDSHAKE_TIMEOUT", 5)) * time.Second,
+		ResponseHeaderTimeout: time.Duration(sherpa.GetEnvIntWithDefault("BP_RESPONSE_HEADER_TIMEOUT", 5)) * time.Second,
+		ExpectContinueTimeout: time.Duration(sherpa.GetEnvIntWithDefault("BP_EXPECT_CONTINUE_TIMEOUT", 1)) * time.Second,
 	}
+}
 
-	rawStr = sherpa.GetEnvWithDefault("BP_DIALER_KEEP_ALIVE", "60")
-	dialerKeepAlive, err := strconv.Atoi(rawStr)
-	if err != nil {
-		return nil, fmt.Errorf("unable to convert BP_DIALER_KEEP_ALIVE=%s to integer\n%w", rawStr, err)
-	}
+func customizeDialerNetwork(logger bard.Logger) string {
+	network := sherpa.GetEnvWithDefault("BP_DIALER_NETWORK", "tcp")
 
-	rawStr = sherpa.GetEnvWithDefault("BP_TLS_HANDSHAKE_TIMEOUT", "5")
-	tlsHandshakeTimeout, err := strconv.Atoi(rawStr)
-	if err != nil {
-		return nil, fmt.Errorf("unable to convert BP_TLS_HANDSHAKE_TIMEOUT=%s to integer\n%w", rawStr, err)
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return network
+	default:
+		logger.Bodyf("%s $BP_DIALER_NETWORK %s is not one of tcp, tcp4, or tcp6, using tcp",
+			color.YellowString("Warning:"), network)
+		return "tcp"
 	}
+}
 
-	rawStr = sherpa.GetEnvWithDefault("BP_RESPONSE_HEADER_TIMEOUT", "5")
-	responseHeaderTimeout, err := strconv.Atoi(rawStr)
-	if err != nil {
-		return nil, fmt.Errorf("unable to convert BP_RESPONSE_HEADER_TIMEOUT=%s to integer\n%w", rawStr, err)
+// maxArtifactBytes returns the value of $BP_MAX_ARTIFACT_SIZE, or 0 if it is not set.  Unlike other numeric settings,
+// a value that is not a non-negative number of bytes is an error rather than silently removing the limit.
+func maxArtifactBytes() (int64, error) {
+	s, ok := os.LookupEnv("BP_MAX_ARTIFACT_SIZE")
+	if !ok {
+		return 0, nil
 	}
 
-	rawStr = sherpa.GetEnvWithDefault("BP_EXPECT_CONTINUE_TIMEOUT", "1")
-	expectContinueTimeout, err := strconv.Atoi(rawStr)
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("unable to convert BP_EXPECT_CONTINUE_TIMEOUT=%s to integer\n%w", rawStr, err)
+		return 0, fmt.Errorf("unable to parse $BP_MAX_ARTIFACT_SIZE %q as a number of bytes\n%w", s, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("$BP_MAX_ARTIFACT_SIZE %d must not be negative", n)
 	}
 
-	return &HttpClientTimeouts{
-		DialerTimeout:         time.Duration(dialerTimeout) * time.Second,
-		DialerKeepAlive:       time.Duration(dialerKeepAlive) * time.Second,
-		TLSHandshakeTimeout:   time.Duration(tlsHandshakeTimeout) * time.Second,
-		ResponseHeaderTimeout: time.Duration(responseHeaderTimeout) * time.Second,
-		ExpectContinueTimeout: time.Duration(expectContinueTimeout) * time.Second,
-	}, nil
+	return n, nil
 }
 
 func (d *DependencyCache) setDependencyMirrors(bindingMirrors map[string]string) {
@@ -164,6 +288,19 @@ func (d *DependencyCache) setDependencyMirrors(bindingMirrors map[string]string)
 		if len(envPair) != 2 {
 			continue
 		}
+		if idSuffix, isIDMirror := strings.CutPrefix(envPair[0], "BP_DEPENDENCY_ID_MIRROR"); isIDMirror {
+			idEncoded, _ := strings.CutPrefix(idSuffix, "_")
+			if idEncoded == "" || strings.ToLower(idEncoded) == "default" {
+				d.Logger.Bodyf("%s with illegal dependency id '%s'. Please use BP_DEPENDENCY_MIRROR to set a default.",
+					color.YellowString("Ignored dependency mirror"), strings.ToLower(idEncoded))
+				continue
+			}
+			if d.DependencyIDMirrors == nil {
+				d.DependencyIDMirrors = map[string]string{}
+			}
+			d.DependencyIDMirrors[decodeHostnameEnv(idEncoded, d)] = envPair[1]
+			continue
+		}
 		hostnameSuffix, isMirror := strings.CutPrefix(envPair[0], "BP_DEPENDENCY_MIRROR")
 		if isMirror {
 			hostnameEncoded, _ := strings.CutPrefix(hostnameSuffix, "_")
@@ -211,264 +348,1464 @@ func filterBindingsByType(bindings libcnb.Bindings, bindingType string) (map[str
 	return filteredBindings, nil
 }
 
-// RequestModifierFunc is a callback that enables modification of a download request before it is sent.  It is often
-// used to set Authorization headers.
-type RequestModifierFunc func(request *http.Request) (*http.Request, error)
+// ReportUnused logs a warning for each of the Mappings and DependencyMirrors that has not been used by Artifact.  It is
+// typically called once all dependencies have been contributed, to surface misconfigured bindings such as a
+// dependency-mapping with an incorrect digest.
+func (d *DependencyCache) ReportUnused() {
+	var mappings, mirrors []string
+	for k := range d.Mappings {
+		if !d.usage.used("mapping", k) {
+			mappings = append(mappings, k)
+		}
+	}
+	for k := range d.DependencyMirrors {
+		if !d.usage.used("mirror", k) {
+			mirrors = append(mirrors, k)
+		}
+	}
+	for k := range d.DependencyIDMirrors {
+		if !d.usage.used("id-mirror", k) {
+			mirrors = append(mirrors, fmt.Sprintf("dependency id %s", k))
+		}
+	}
+	sort.Strings(mappings)
+	sort.Strings(mirrors)
 
-// Artifact returns the path to the artifact.  Resolution of that path follows three tiers:
-//
-// 1. CachePath
-// 2. DownloadPath
-// 3. Download from URI
-//
-// If the BuildpackDependency's SHA256 is not set, the download can never be verified to be up to date and will always
-// download, skipping all the caches.
-func (d *DependencyCache) Artifact(dependency BuildpackDependency, mods ...RequestModifierFunc) (*os.File, error) {
+	for _, m := range mappings {
+		d.Logger.Headerf("%s Dependency mapping for %s was never used", color.New(color.FgYellow, color.Bold).Sprint("Warning:"), m)
+	}
+	for _, m := range mirrors {
+		d.Logger.Headerf("%s Dependency mirror for %s was never used", color.New(color.FgYellow, color.Bold).Sprint("Warning:"), m)
+	}
+}
 
-	var (
-		actual    BuildpackDependency
-		artifact  string
-		file      string
-		isBinding bool
-		uri       = dependency.URI
-		urlP      *url.URL
-	)
+// Validate checks that each of the Mappings, DependencyMirrors and DependencyIDMirrors is a valid URI with a supported
+// scheme, https or file, and if ProbeOnValidate is set, that it can be reached.  It is typically called before any
+// dependencies are contributed so that misconfiguration is caught early rather than part way through a build.  All
+// URIs are checked, and any problems are returned together.
+func (d DependencyCache) Validate() error {
+	var errs []error
 
-	for d, u := range d.Mappings {
-		if d == dependency.SHA256 {
-			isBinding = true
-			uri = u
-			break
+	for _, k := range sortedKeys(d.Mappings) {
+		if err := d.validateURI(d.Mappings[k], false); err != nil {
+			errs = append(errs, fmt.Errorf("invalid dependency mapping for %s\n%w", k, err))
 		}
 	}
 
-	urlP, err := url.Parse(uri)
+	for _, k := range sortedKeys(d.DependencyMirrors) {
+		for _, m := range splitMirrors(d.DependencyMirrors[k]) {
+			if err := d.validateURI(parseMirror(m)["mirror"], true); err != nil {
+				errs = append(errs, fmt.Errorf("invalid dependency mirror for %s\n%w", k, err))
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(d.DependencyIDMirrors) {
+		for _, m := range splitMirrors(d.DependencyIDMirrors[k]) {
+			if err := d.validateURI(parseMirror(m)["mirror"], true); err != nil {
+				errs = append(errs, fmt.Errorf("invalid dependency mirror for dependency id %s\n%w", k, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateURI checks that uri is a valid https or file URI and, if ProbeOnValidate is set, that it can be reached.
+// Mirrors are only required to be reachable, while mappings must also respond successfully.
+func (d DependencyCache) validateURI(uri string, mirror bool) error {
+	u, err := url.Parse(uri)
 	if err != nil {
-		d.Logger.Debugf("URI format invalid\n%w", err)
-		return nil, fmt.Errorf("unable to parse URI. see DEBUG log level")
+		return fmt.Errorf("unable to parse URI\n%w", err)
 	}
 
-	mirror := d.DependencyMirrors["default"]
-	mirrorHostSpecific := d.DependencyMirrors[urlP.Hostname()]
-	if mirrorHostSpecific != "" {
-		mirror = mirrorHostSpecific
+	switch strings.ToLower(u.Scheme) {
+	case "https", "file":
+	default:
+		return fmt.Errorf("unsupported scheme in %s, use https or file", u.Redacted())
 	}
 
-	if isBinding && mirror != "" {
-		d.Logger.Bodyf("Both dependency mirror and bindings are present. %s Please remove dependency map bindings if you wish to use the mirror.",
-			color.YellowString("Mirror is being ignored."))
-	} else {
-		d.setDependencyMirror(urlP, mirror)
+	if !d.ProbeOnValidate {
+		return nil
 	}
 
-	if dependency.SHA256 == "" {
-		d.Logger.Headerf("%s Dependency has no SHA256. Skipping cache.",
-			color.New(color.FgYellow, color.Bold).Sprint("Warning:"))
+	if strings.EqualFold(u.Scheme, "file") {
+		path := u.Path
+		if i := strings.Index(path, "{originalHost}"); i >= 0 {
+			path = path[:i]
+		}
 
-		d.Logger.Bodyf("%s from %s", color.YellowString("Downloading"), urlP.Redacted())
-		artifact = filepath.Join(d.DownloadPath, filepath.Base(uri))
-		if err := d.download(urlP, artifact, mods...); err != nil {
-			return nil, fmt.Errorf("unable to download %s\n%w", urlP.Redacted(), err)
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("unable to stat %s\n%w", path, err)
 		}
 
-		return os.Open(artifact)
+		return nil
 	}
 
-	file = filepath.Join(d.CachePath, fmt.Sprintf("%s.toml", dependency.SHA256))
-	b, err := os.ReadFile(file)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
-	}
-	if err := toml.Unmarshal(b, &actual); err != nil {
-		return nil, fmt.Errorf("unable to decode download metadata %s\n%w", file, err)
+	probe := *u
+	if mirror {
+		probe.Path, probe.RawPath, probe.RawQuery = "/", "", ""
 	}
 
-	if dependency.Equals(actual) {
-		d.Logger.Bodyf("%s cached download from buildpack", color.GreenString("Reusing"))
-		return os.Open(filepath.Join(d.CachePath, dependency.SHA256, filepath.Base(urlP.Path)))
+	req, err := http.NewRequest(http.MethodHead, probe.String(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create new HEAD request for %s\n%w", probe.Redacted(), err)
 	}
 
-	file = filepath.Join(d.DownloadPath, fmt.Sprintf("%s.toml", dependency.SHA256))
-	b, err = os.ReadFile(file)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
-	}
-	if err := toml.Unmarshal(b, &actual); err != nil {
-		return nil, fmt.Errorf("unable to decode download metadata %s\n%w", file, err)
+	if d.UserAgent != "" {
+		req.Header.Set("User-Agent", d.UserAgent)
 	}
 
-	if dependency.Equals(actual) {
-		d.Logger.Bodyf("%s previously cached download", color.GreenString("Reusing"))
-		return os.Open(filepath.Join(d.DownloadPath, dependency.SHA256, filepath.Base(urlP.Path)))
+	resp, err := d.httpClient(&probe).Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to request %s\n%w", probe.Redacted(), err)
 	}
+	resp.Body.Close()
 
-	d.Logger.Bodyf("%s from %s", color.YellowString("Downloading"), urlP.Redacted())
-	artifact = filepath.Join(d.DownloadPath, dependency.SHA256, filepath.Base(uri))
-	if err := d.download(urlP, artifact, mods...); err != nil {
-		return nil, fmt.Errorf("unable to download %s\n%w", urlP.Redacted(), err)
+	if !mirror && resp.StatusCode >= 400 && resp.StatusCode != http.StatusMethodNotAllowed {
+		return DownloadStatusError{URI: probe.Redacted(), StatusCode: resp.StatusCode}
 	}
 
-	d.Logger.Body("Verifying checksum")
-	if err := d.verify(artifact, dependency.SHA256); err != nil {
-		return nil, err
-	}
+	return nil
+}
 
-	file = filepath.Join(d.DownloadPath, fmt.Sprintf("%s.toml", dependency.SHA256))
-	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
-		return nil, fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(file), err)
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	out, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
-	if err != nil {
-		return nil, fmt.Errorf("unable to open file %s\n%w", file, err)
+// dependencyCacheUsage records the keys of the Mappings and DependencyMirrors that have been used.
+type dependencyCacheUsage struct {
+	mutex sync.Mutex
+	keys  map[string]bool
+}
+
+func (u *dependencyCacheUsage) use(kind string, key string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.keys == nil {
+		u.keys = map[string]bool{}
 	}
-	defer out.Close()
+	u.keys[kind+"/"+key] = true
+}
 
-	if err := toml.NewEncoder(out).Encode(dependency); err != nil {
-		return nil, fmt.Errorf("unable to write metadata %s\n%w", file, err)
+func (u *dependencyCacheUsage) used(kind string, key string) bool {
+	if u == nil {
+		return false
 	}
 
-	return os.Open(artifact)
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return u.keys[kind+"/"+key]
 }
 
-func (d DependencyCache) download(url *url.URL, destination string, mods ...RequestModifierFunc) error {
-	if url.Scheme == "file" {
-		return d.downloadFile(url.Path, destination, mods...)
-	}
+// ChecksumMismatchError is returned when the checksum of a download does not match the expected checksum.  Any
+// ChecksumMismatchError matches another with errors.Is.
+type ChecksumMismatchError struct {
+	// Path is the path of the download.
+	Path string
 
-	return d.downloadHttp(url, destination, mods...)
+	// Expected is the expected checksum, either a bare SHA256 or in the form algorithm:hex.
+	Expected string
+
+	// Actual is the checksum of the download, in the same form as Expected.
+	Actual string
 }
 
-func (d DependencyCache) downloadFile(source string, destination string, mods ...RequestModifierFunc) error {
-	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
-		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+func (c ChecksumMismatchError) Error() string {
+	algorithm, _ := splitChecksum(c.Expected)
+	return fmt.Sprintf("%s for %s %s does not match expected %s", algorithm, c.Path, c.Actual, c.Expected)
+}
+
+// Is indicates whether target is a ChecksumMismatchError.
+func (ChecksumMismatchError) Is(target error) bool {
+	_, ok := target.(ChecksumMismatchError)
+	return ok
+}
+
+// DownloadStatusError is returned when a download responds with a non-2xx status code.  It matches a
+// DownloadStatusError with the same StatusCode, or with no StatusCode, with errors.Is.
+type DownloadStatusError struct {
+	// URI is the redacted URI of the download.
+	URI string
+
+	// StatusCode is the status code of the response.
+	StatusCode int
+}
+
+func (d DownloadStatusError) Error() string {
+	return fmt.Sprintf("could not download %s: %d", d.URI, d.StatusCode)
+}
+
+// Is indicates whether target is a DownloadStatusError with the same StatusCode or with no StatusCode.
+func (d DownloadStatusError) Is(target error) bool {
+	t, ok := target.(DownloadStatusError)
+	return ok && (t.StatusCode == 0 || t.StatusCode == d.StatusCode)
+}
+
+// ArtifactTooLargeError is returned when a download is larger than the MaxArtifactBytes of a DependencyCache.  Any
+// ArtifactTooLargeError matches another with errors.Is.
+type ArtifactTooLargeError struct {
+	// URI is the redacted URI of the download.
+	URI string
+
+	// Limit is the maximum size of an artifact in bytes.
+	Limit int64
+
+	// Size is the size of the download in bytes, if it is known before downloading, otherwise zero.
+	Size int64
+}
+
+func (a ArtifactTooLargeError) Error() string {
+	if a.Size > 0 {
+		return fmt.Sprintf("%s is %d bytes, larger than the maximum artifact size of %d bytes", a.URI, a.Size, a.Limit)
 	}
 
-	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("unable to open destination file %s\n%w", destination, err)
+	return fmt.Sprintf("%s is larger than the maximum artifact size of %d bytes", a.URI, a.Limit)
+}
+
+// Is indicates whether target is an ArtifactTooLargeError.
+func (ArtifactTooLargeError) Is(target error) bool {
+	_, ok := target.(ArtifactTooLargeError)
+	return ok
+}
+
+// NotCachedError is returned when a dependency is to be copied from a file URI, such as a file mirror, that does not
+// contain it.  Any NotCachedError matches another with errors.Is.
+type NotCachedError struct {
+	// Path is the path the dependency was expected at.
+	Path string
+}
+
+func (n NotCachedError) Error() string {
+	return fmt.Sprintf("%s is not cached", n.Path)
+}
+
+// Is indicates whether target is a NotCachedError.
+func (NotCachedError) Is(target error) bool {
+	_, ok := target.(NotCachedError)
+	return ok
+}
+
+// RequestModifierFunc is a callback that enables modification of a download request before it is sent.  It is often
+// used to set Authorization headers.
+type RequestModifierFunc func(request *http.Request) (*http.Request, error)
+
+// withHeaders returns mods preceded by a RequestModifierFunc that sets the Headers of the dependency, so that they are
+// applied after the UserAgent and before any other modifications.  Only the names of the headers are logged, as their
+// values are often credentials.
+func (d DependencyCache) withHeaders(dependency BuildpackDependency, mods []RequestModifierFunc) []RequestModifierFunc {
+	if len(dependency.Headers) == 0 {
+		return mods
 	}
-	defer out.Close()
 
-	input, err := os.Open(source)
+	names := sortedKeys(dependency.Headers)
+	d.Logger.Debugf("Setting request headers %s", strings.Join(names, ", "))
+
+	return append([]RequestModifierFunc{func(request *http.Request) (*http.Request, error) {
+		for _, n := range names {
+			request.Header.Set(n, dependency.Headers[n])
+		}
+		return request, nil
+	}}, mods...)
+}
+
+// Artifact returns the path to the artifact.  Resolution of that path follows three tiers:
+//
+// 1. CachePath
+// 2. DownloadPath
+// 3. Download from URI
+//
+// If PromoteDownloads is set, a verified download is also copied into CachePath.
+//
+// Any Headers of the BuildpackDependency are set on download requests, after the UserAgent and before mods.
+//
+// Placeholders in the URI, such as ${arch} or ${BP_EXAMPLE}, are expanded before it is used.  See
+// ConfigurationResolver.
+//
+// Downloads are verified against the BuildpackDependency's SHA256, which may name another algorithm in the form
+// algorithm:hex, such as sha512:<hex>.
+//
+// Download failures can be distinguished with errors.Is or errors.As and ArtifactTooLargeError, ChecksumMismatchError,
+// DownloadStatusError, or NotCachedError.
+//
+// If the BuildpackDependency's SHA256 is not set, the download can never be verified to be up to date and will always
+// download, skipping all the caches.  The SHA256 of the download is logged and the download is stored in DownloadPath
+// under that SHA256, so it is reused once the SHA256 is added to the BuildpackDependency.  The SHA256 is also recorded
+// against the URI, so that Prune keeps the download.  If the server returned an ETag or Last-Modified header, it is
+// recorded as well and sent as If-None-Match or If-Modified-Since on the next request, and a 304 Not Modified response
+// reuses the previous download.
+func (d *DependencyCache) Artifact(dependency BuildpackDependency, mods ...RequestModifierFunc) (*os.File, error) {
+
+	var (
+		artifact string
+		file     string
+	)
+
+	mods = d.withHeaders(dependency, mods)
+
+	uri, urlP, candidates, err := d.candidates(dependency)
 	if err != nil {
-		return fmt.Errorf("unable to open source file %s\n%w", source, err)
+		return nil, err
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(out, input); err != nil {
-		return fmt.Errorf("unable to copy from %s to %s\n%w", source, destination, err)
-	}
+	if dependency.SHA256 == "" {
+		d.Logger.Headerf("%s Dependency has no SHA256. Skipping cache.",
+			color.New(color.FgYellow, color.Bold).Sprint("Warning:"))
 
-	return nil
-}
+		file = filepath.Join(d.DownloadPath, fmt.Sprintf("%s.validators.toml", validatorsKey(dependency.URI)))
+		var previous httpValidators
+		b, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+		if err := toml.Unmarshal(b, &previous); err != nil {
+			return nil, fmt.Errorf("unable to decode validators %s\n%w", file, err)
+		}
 
-func (d DependencyCache) downloadHttp(url *url.URL, destination string, mods ...RequestModifierFunc) error {
-	var httpClient *http.Client
-	if (strings.EqualFold(url.Hostname(), "localhost")) || (strings.EqualFold(url.Hostname(), "127.0.0.1")) {
-		httpClient = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
+		cached := filepath.Join(d.DownloadPath, ChecksumDirectory(previous.SHA256), artifactName(dependency, uri))
+		if previous.SHA256 != "" && (previous.ETag != "" || previous.LastModified != "") {
+			if _, err := os.Stat(cached); err == nil {
+				mods = append(mods, previous.conditional)
+			}
 		}
-	} else {
-		httpClient = &http.Client{
-			Transport: &http.Transport{
-				Dial: (&net.Dialer{
-					Timeout:   d.HttpClientTimeouts.DialerTimeout,
-					KeepAlive: d.HttpClientTimeouts.DialerKeepAlive,
-				}).Dial,
-				TLSHandshakeTimeout:   d.HttpClientTimeouts.TLSHandshakeTimeout,
-				ResponseHeaderTimeout: d.HttpClientTimeouts.ResponseHeaderTimeout,
-				ExpectContinueTimeout: d.HttpClientTimeouts.ExpectContinueTimeout,
-				Proxy:                 http.ProxyFromEnvironment,
-			},
+
+		current := &httpValidators{}
+		artifact = filepath.Join(d.DownloadPath, artifactName(dependency, uri))
+		checksum, err := d.observedDownload(dependency, candidates, artifact, "", current, mods...)
+		if errors.Is(err, errNotModified) {
+			d.Logger.Bodyf("%s previous download, not modified", color.GreenString("Reusing"))
+			d.observeCacheHit(dependency)
+			return os.Open(cached)
+		} else if err != nil {
+			return nil, err
+		}
+
+		d.Logger.Bodyf("Downloaded dependency has SHA256 %s", checksum)
+		dependency.SHA256 = checksum
+
+		destination := filepath.Join(d.DownloadPath, ChecksumDirectory(dependency.SHA256), artifactName(dependency, uri))
+		if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+			return nil, fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+		}
+		if err := os.Rename(artifact, destination); err != nil {
+			return nil, fmt.Errorf("unable to move %s to %s\n%w", artifact, destination, err)
+		}
+
+		if err := d.writeMetadata(dependency, destination); err != nil {
+			return nil, err
 		}
+
+		current.SHA256 = checksum
+
+		buf := &bytes.Buffer{}
+		if err := toml.NewEncoder(buf).Encode(current); err != nil {
+			return nil, fmt.Errorf("unable to encode validators %s\n%w", file, err)
+		}
+
+		if err := sherpa.WriteFileAtomic(file, buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("unable to write validators %s\n%w", file, err)
+		}
+
+		return os.Open(destination)
 	}
 
-	req, err := http.NewRequest("GET", url.String(), nil)
-	if err != nil {
-		return fmt.Errorf("unable to create new GET request for %s\n%w", url.Redacted(), err)
+	if _, err := newChecksumHash(dependency.SHA256); err != nil {
+		return nil, err
 	}
 
-	if d.UserAgent != "" {
-		req.Header.Set("User-Agent", d.UserAgent)
+	if cached, ok, err := d.cached(dependency, urlP); err != nil {
+		return nil, err
+	} else if ok {
+		return os.Open(cached)
 	}
 
-	for _, m := range mods {
-		req, err = m(req)
-		if err != nil {
-			return fmt.Errorf("unable to modify request\n%w", err)
-		}
+	artifact = filepath.Join(d.DownloadPath, ChecksumDirectory(dependency.SHA256), artifactName(dependency, uri))
+	if _, err := d.observedDownload(dependency, candidates, artifact, dependency.SHA256, nil, mods...); err != nil {
+		return nil, err
 	}
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("unable to request %s\n%w", url.Redacted(), err)
+	if err := d.writeMetadata(dependency, artifact); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("could not download %s: %d", url.Redacted(), resp.StatusCode)
+	return os.Open(artifact)
+}
+
+// ArtifactReader returns a reader of the artifact.  If the artifact is in CachePath or DownloadPath, that file is
+// opened.  Otherwise it is streamed from the first location that responds successfully, without being written to disk,
+// and the SHA256 of the stream is verified once it has been read to the end.  A mismatch is returned by Read as a
+// ChecksumMismatchError in place of io.EOF, so the content must not be trusted until the reader has been consumed.
+//
+// If the BuildpackDependency's SHA256 is not set, the stream cannot be verified and the artifact is downloaded as it is
+// by Artifact.
+func (d *DependencyCache) ArtifactReader(dependency BuildpackDependency, mods ...RequestModifierFunc) (io.ReadCloser, error) {
+	if dependency.SHA256 == "" {
+		return d.Artifact(dependency, mods...)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
-		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+	h, err := newChecksumHash(dependency.SHA256)
+	if err != nil {
+		return nil, err
 	}
 
-	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	mods = d.withHeaders(dependency, mods)
+
+	uri, urlP, candidates, err := d.candidates(dependency)
 	if err != nil {
-		return fmt.Errorf("unable to open file %s\n%w", destination, err)
+		return nil, err
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return fmt.Errorf("unable to copy from %s to %s\n%w", url.Redacted(), destination, err)
+	if cached, ok, err := d.cached(dependency, urlP); err != nil {
+		return nil, err
+	} else if ok {
+		return os.Open(cached)
 	}
 
-	return nil
-}
+	if d.Observer != nil {
+		d.Observer.OnCacheMiss(dependency)
+	}
 
-func (DependencyCache) verify(path string, expected string) error {
-	s := sha256.New()
+	for _, u := range candidates {
+		d.Logger.Bodyf("%s from %s", color.YellowString("Streaming"), u.Redacted())
 
-	in, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("unable to verify %s\n%w", path, err)
+		in, err := d.open(u, mods...)
+		if err != nil {
+			err = fmt.Errorf("unable to download %s\n%w", u.Redacted(), err)
+			if u == candidates[len(candidates)-1] {
+				return nil, err
+			}
+
+			d.Logger.Bodyf("%s from %s, trying next location", color.YellowString("Download failed"), u.Redacted())
+			d.Logger.Debugf("%s", err)
+			continue
+		}
+
+		return &verifyingReader{
+			ReadCloser: in,
+			dependency: dependency,
+			hash:       h,
+			limit:      d.MaxArtifactBytes,
+			observer:   d.Observer,
+			path:       uri,
+			start:      time.Now(),
+		}, nil
 	}
-	defer in.Close()
 
-	if _, err := io.Copy(s, in); err != nil {
-		return fmt.Errorf("unable to read %s\n%w", path, err)
+	return nil, fmt.Errorf("no locations to download %s from", uri)
+}
+
+// ResolveURI returns the redacted URI the dependency would first be downloaded from, after applying Mappings,
+// DependencyMirrors and URIRewriter in the same order as Artifact, and whether any of them changed the URI of the
+// dependency.  Nothing is downloaded, and the Mappings and DependencyMirrors are not recorded as used by ReportUnused.
+func (d *DependencyCache) ResolveURI(dependency BuildpackDependency) (string, bool, error) {
+	original, err := d.expandURI(dependency.URI)
+	if err != nil {
+		return "", false, err
 	}
 
-	actual := hex.EncodeToString(s.Sum(nil))
+	c := *d
+	c.usage = &dependencyCacheUsage{}
 
-	if expected != actual {
-		return fmt.Errorf("sha256 for %s %s does not match expected %s", path, actual, expected)
+	_, _, candidates, err := c.candidates(dependency)
+	if err != nil {
+		return "", false, err
 	}
 
-	return nil
+	return candidates[0].Redacted(), candidates[0].String() != original, nil
 }
 
-func (d DependencyCache) setDependencyMirror(urlD *url.URL, mirror string) {
-	if mirror != "" {
-		d.Logger.Bodyf("%s Download URIs will be overridden.", color.GreenString("Dependency mirror found."))
-		mirrorArgs := parseMirror(mirror)
-		urlOverride, err := url.ParseRequestURI(mirrorArgs["mirror"])
+// Prune removes the <SHA256> directories and <SHA256>.toml metadata files from CachePath and DownloadPath that do not
+// belong to one of the dependencies to keep, so that a long-lived cache stays bounded.  For dependencies without a
+// SHA256, the most recent download of their URI is kept.  Links in DownloadPath/by-id to removed downloads are also
+// removed.  Only names shaped like a checksum are considered, as DownloadPath defaults to os.TempDir(), and any other
+// files are left unchanged.
+func (d DependencyCache) Prune(keep []BuildpackDependency) error {
+	var (
+		checksums  = map[string]bool{}
+		validators = map[string]bool{}
+	)
 
-		if strings.ToLower(urlOverride.Scheme) == "https" || strings.ToLower(urlOverride.Scheme) == "file" {
-			urlD.Scheme = urlOverride.Scheme
-			urlD.User = urlOverride.User
-			urlD.Path = strings.Replace(urlOverride.Path, "{originalHost}", urlD.Hostname(), 1) + strings.Replace(urlD.Path, mirrorArgs["skip-path"], "", 1)
-			urlD.Host = urlOverride.Host
-		} else {
-			d.Logger.Debugf("Dependency mirror URI is invalid: %s\n%w", mirror, err)
+	for _, dependency := range keep {
+		if dependency.SHA256 != "" {
+			checksums[ChecksumDirectory(dependency.SHA256)] = true
+			continue
+		}
+
+		key := validatorsKey(dependency.URI)
+		validators[key] = true
+
+		var previous httpValidators
+		b, err := os.ReadFile(filepath.Join(d.DownloadPath, fmt.Sprintf("%s.validators.toml", key)))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to read validators of %s\n%w", dependency.ID, err)
+		}
+		if err := toml.Unmarshal(b, &previous); err != nil {
+			return fmt.Errorf("unable to decode validators of %s\n%w", dependency.ID, err)
+		}
+		if previous.SHA256 != "" {
+			checksums[ChecksumDirectory(previous.SHA256)] = true
+		}
+	}
+
+	for _, root := range []string{d.CachePath, d.DownloadPath} {
+		if root == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(root)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", root, err)
+		}
+
+		for _, e := range entries {
+			name := e.Name()
+
+			var stale bool
+			if e.IsDir() {
+				stale = checksumName.MatchString(name) && !checksums[name]
+			} else if key, ok := strings.CutSuffix(name, ".validators.toml"); ok {
+				stale = checksumName.MatchString(key) && !validators[key]
+			} else if checksum, ok := strings.CutSuffix(name, ".toml"); ok {
+				stale = checksumName.MatchString(checksum) && !checksums[checksum]
+			}
+
+			if !stale {
+				continue
+			}
+
+			file := filepath.Join(root, name)
+			d.Logger.Debugf("Pruning %s", file)
+			if err := os.RemoveAll(file); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", file, err)
+			}
+		}
+	}
+
+	if d.DownloadPath == "" {
+		return nil
+	}
+
+	return pruneLinks(filepath.Join(d.DownloadPath, "by-id"))
+}
+
+// checksumName matches the names that Prune may remove: a bare SHA256, or another algorithm as <algorithm>-<hex> or
+// <algorithm>:<hex>.
+var checksumName = regexp.MustCompile(`^(?:[0-9a-fA-F]{64}|sha384[-:][0-9a-fA-F]{96}|sha512[-:][0-9a-fA-F]{128})$`)
+
+// pruneLinks removes the links in the by-id directory whose targets no longer exist, and any directories that are
+// left empty.
+func pruneLinks(root string) error {
+	ids, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", root, err)
+	}
+
+	for _, id := range ids {
+		dir := filepath.Join(root, id.Name())
+
+		versions, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", dir, err)
+		}
+
+		remaining := len(versions)
+		for _, v := range versions {
+			link := filepath.Join(dir, v.Name())
+			if _, err := os.Stat(link); !os.IsNotExist(err) {
+				continue
+			}
+
+			if err := os.Remove(link); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", link, err)
+			}
+			remaining--
+		}
+
+		if remaining == 0 {
+			if err := os.Remove(dir); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", dir, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Prefetch downloads and verifies each of the dependencies into CachePath, so that they are reused by later builds.
+// Dependencies that are already cached are skipped, as are dependencies without a SHA256 since they can never be
+// reused.  Up to concurrency dependencies are downloaded at the same time, and the Observer may be called
+// concurrently.  All dependencies are attempted, and any errors are returned together.
+func (d *DependencyCache) Prefetch(dependencies []BuildpackDependency, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if d.usage == nil {
+		d.usage = &dependencyCacheUsage{}
+	}
+
+	cache := *d
+	cache.DownloadPath = d.CachePath
+	cache.PromoteDownloads = false
+
+	var (
+		errs  = make([]error, len(dependencies))
+		slots = make(chan struct{}, concurrency)
+		wg    sync.WaitGroup
+	)
+
+	for i, dependency := range dependencies {
+		if dependency.SHA256 == "" {
+			d.Logger.Bodyf("%s %s %s, dependency has no SHA256", color.YellowString("Skipping"), dependency.ID, dependency.Version)
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, dependency BuildpackDependency) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			f, err := cache.Artifact(dependency)
+			if err != nil {
+				errs[i] = fmt.Errorf("unable to prefetch %s %s\n%w", dependency.ID, dependency.Version, err)
+				return
+			}
+			_ = f.Close()
+		}(i, dependency)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// open opens url for reading, either a file or the body of an HTTP response.
+func (d DependencyCache) open(url *url.URL, mods ...RequestModifierFunc) (io.ReadCloser, error) {
+	if url.Scheme == "file" {
+		in, err := os.Open(url.Path)
+		if os.IsNotExist(err) {
+			return nil, NotCachedError{Path: url.Path}
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to open source file %s\n%w", url.Path, err)
+		}
+		return in, nil
+	}
+
+	resp, err := d.get(url, mods...)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.decode(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to decode %s\n%w", url.Redacted(), err)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}, nil
+}
+
+// verifyingReader hashes everything read from it and, at the end of the stream, returns a ChecksumMismatchError if the
+// checksum does not match the dependency's.  If limit is set, reading more than limit bytes returns an
+// ArtifactTooLargeError.
+type verifyingReader struct {
+	io.ReadCloser
+
+	dependency BuildpackDependency
+	hash       hash.Hash
+	limit      int64
+	observer   DependencyCacheObserver
+	path       string
+	size       int64
+	start      time.Time
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.size += int64(n)
+	_, _ = v.hash.Write(p[:n])
+
+	if v.limit > 0 && v.size > v.limit {
+		return n, ArtifactTooLargeError{URI: v.path, Limit: v.limit}
+	}
+
+	if err != io.EOF {
+		return n, err
+	}
+
+	_, expected := splitChecksum(v.dependency.SHA256)
+	if actual := hex.EncodeToString(v.hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return n, ChecksumMismatchError{Path: v.path, Expected: v.dependency.SHA256, Actual: formatChecksum(v.dependency.SHA256, actual)}
+	}
+
+	if v.observer != nil {
+		v.observer.OnDownloadComplete(v.dependency, v.size, time.Since(v.start))
+		v.observer = nil
+	}
+
+	return n, io.EOF
+}
+
+// candidates returns the URI of the dependency after applying Mappings, the URL of the first location to download it
+// from, and every location to download it from in order, after applying DependencyMirrors and URIRewriter.
+func (d *DependencyCache) candidates(dependency BuildpackDependency) (string, *url.URL, []*url.URL, error) {
+	var isBinding bool
+
+	if d.usage == nil {
+		d.usage = &dependencyCacheUsage{}
+	}
+
+	uri, err := d.expandURI(dependency.URI)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	for k, u := range d.Mappings {
+		if k == dependency.SHA256 || k == ChecksumDirectory(dependency.SHA256) {
+			isBinding = true
+			uri = u
+			d.usage.use("mapping", k)
+			break
+		}
+	}
+
+	urlP, err := url.Parse(uri)
+	if err != nil {
+		d.Logger.Debugf("URI format invalid\n%w", err)
+		return "", nil, nil, fmt.Errorf("unable to parse URI. see DEBUG log level")
+	}
+
+	mirror, mirrorKind, mirrorKey := d.DependencyMirrors["default"], "mirror", "default"
+	if mirrorHostSpecific := d.DependencyMirrors[urlP.Hostname()]; mirrorHostSpecific != "" {
+		mirror, mirrorKey = mirrorHostSpecific, urlP.Hostname()
+	}
+	if mirrorIDSpecific := d.DependencyIDMirrors[strings.ToLower(dependency.ID)]; mirrorIDSpecific != "" {
+		mirror, mirrorKind, mirrorKey = mirrorIDSpecific, "id-mirror", strings.ToLower(dependency.ID)
+	}
+	if mirror != "" && !isBinding {
+		d.usage.use(mirrorKind, mirrorKey)
+	}
+
+	candidates := []*url.URL{urlP}
+	if isBinding && mirror != "" {
+		d.Logger.Bodyf("Both dependency mirror and bindings are present. %s Please remove dependency map bindings if you wish to use the mirror.",
+			color.YellowString("Mirror is being ignored."))
+	} else if mirrors := splitMirrors(mirror); len(mirrors) > 1 {
+		candidates = nil
+		for _, m := range mirrors {
+			u := *urlP
+			d.setDependencyMirror(&u, m)
+			candidates = append(candidates, &u)
+		}
+		candidates = append(candidates, urlP)
+		urlP = candidates[0]
+	} else {
+		d.setDependencyMirror(urlP, mirror)
+	}
+
+	if d.URIRewriter != nil {
+		for i, c := range candidates {
+			u, err := d.URIRewriter(c)
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("unable to rewrite URI %s\n%w", c.Redacted(), err)
+			}
+			d.Logger.Debugf("Rewrote URI %s to %s", c.Redacted(), u.Redacted())
+			candidates[i] = u
+		}
+	}
+
+	return uri, urlP, candidates, nil
+}
+
+// uriPlaceholder matches a ${NAME} placeholder in a dependency URI.
+var uriPlaceholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandURI replaces each ${NAME} placeholder in uri.  ${arch} is the architecture dependencies are resolved for, and
+// any other name is resolved from the environment or, if ConfigurationResolver is set, the default of a configuration.
+// A placeholder that cannot be resolved is an error.
+func (d DependencyCache) expandURI(uri string) (string, error) {
+	var missing []string
+
+	expanded := uriPlaceholder.ReplaceAllStringFunc(uri, func(placeholder string) string {
+		name := uriPlaceholder.FindStringSubmatch(placeholder)[1]
+
+		if name == "arch" {
+			return archFromSystem()
+		}
+
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+
+		if d.ConfigurationResolver != nil {
+			for _, c := range d.ConfigurationResolver.Configurations {
+				if c.Name == name {
+					return c.Default
+				}
+			}
+		}
+
+		missing = append(missing, name)
+		return placeholder
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unable to expand URI %s, %s not defined", uri, strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
+// cached returns the path of the dependency in CachePath or DownloadPath, and whether it was found in either.
+func (d DependencyCache) cached(dependency BuildpackDependency, urlP *url.URL) (string, bool, error) {
+	for _, c := range []struct {
+		path    string
+		message string
+	}{
+		{d.CachePath, "cached download from buildpack"},
+		{d.DownloadPath, "previously cached download"},
+	} {
+		file := filepath.Join(c.path, fmt.Sprintf("%s.toml", ChecksumDirectory(dependency.SHA256)))
+		b, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return "", false, fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+
+		var actual BuildpackDependency
+		if err := toml.Unmarshal(b, &actual); err != nil {
+			return "", false, fmt.Errorf("unable to decode download metadata %s\n%w", file, err)
+		}
+
+		if !dependency.Equals(actual) {
+			continue
+		}
+
+		artifact := filepath.Join(c.path, ChecksumDirectory(dependency.SHA256), artifactName(dependency, urlP.Path))
+		if d.VerifyOnReuse && c.path == d.CachePath {
+			if err := verifyChecksum(artifact, "", dependency.SHA256); err != nil {
+				d.Logger.Headerf("%s Unable to reuse %s\n%s",
+					color.New(color.FgYellow, color.Bold).Sprint("Warning:"), c.message, err)
+				continue
+			}
+		}
+
+		d.Logger.Bodyf("%s %s", color.GreenString("Reusing"), c.message)
+		d.observeCacheHit(dependency)
+		return artifact, true, nil
+	}
+
+	return "", false, nil
+}
+
+// writeMetadata writes the metadata for a verified artifact to DownloadPath and, if PromoteDownloads is set, promotes
+// the artifact into CachePath.
+func (d DependencyCache) writeMetadata(dependency BuildpackDependency, artifact string) error {
+	file := filepath.Join(d.DownloadPath, fmt.Sprintf("%s.toml", ChecksumDirectory(dependency.SHA256)))
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(dependency); err != nil {
+		return fmt.Errorf("unable to encode metadata %s\n%w", file, err)
+	}
+
+	if err := sherpa.WriteFileAtomic(file, buf.Bytes(), 0755); err != nil {
+		return fmt.Errorf("unable to write metadata %s\n%w", file, err)
+	}
+
+	if d.PromoteDownloads {
+		if err := d.promote(dependency, artifact, buf.Bytes()); err != nil {
+			d.Logger.Bodyf("%s unable to promote download to buildpack cache", color.YellowString("Warning:"))
+			d.Logger.Debugf("%s", err)
+		}
+	}
+
+	if d.LinkDownloadsByID {
+		if err := d.linkByID(dependency); err != nil {
+			d.Logger.Bodyf("%s unable to link download by id", color.YellowString("Warning:"))
+			d.Logger.Debugf("%s", err)
+		}
+	}
+
+	return nil
+}
+
+// linkByID links DownloadPath/by-id/<ID>/<VERSION> to the DownloadPath/<SHA256> directory of the dependency, replacing
+// any existing link.  The link is relative so that it remains valid if DownloadPath is moved.
+func (d DependencyCache) linkByID(dependency BuildpackDependency) error {
+	version := dependency.Version
+	if version == "" {
+		version = "unknown"
+	}
+
+	link := filepath.Join(d.DownloadPath, "by-id", byIDName(dependency.ID), byIDName(version))
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(link), err)
+	}
+
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove existing link %s\n%w", link, err)
+	}
+
+	target := filepath.Join("..", "..", ChecksumDirectory(dependency.SHA256))
+	if err := os.Symlink(target, link); err != nil {
+		return fmt.Errorf("unable to link %s to %s\n%w", link, target, err)
+	}
+
+	return nil
+}
+
+// byIDName returns s with path separators replaced, so that it can be used as a single path element.
+func byIDName(s string) string {
+	return strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(s)
+}
+
+// promote copies a verified artifact and its metadata into CachePath.  The metadata is written last so that the
+// artifact is only ever reused once it has been completely copied.
+func (d DependencyCache) promote(dependency BuildpackDependency, artifact string, metadata []byte) error {
+	in, err := os.Open(artifact)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", artifact, err)
+	}
+	defer in.Close()
+
+	destination := filepath.Join(d.CachePath, ChecksumDirectory(dependency.SHA256), filepath.Base(artifact))
+	if err := sherpa.CopyFile(in, destination); err != nil {
+		return fmt.Errorf("unable to copy %s to %s\n%w", artifact, destination, err)
+	}
+
+	file := filepath.Join(d.CachePath, fmt.Sprintf("%s.toml", ChecksumDirectory(dependency.SHA256)))
+	if err := sherpa.WriteFileAtomic(file, metadata, 0755); err != nil {
+		return fmt.Errorf("unable to write metadata %s\n%w", file, err)
+	}
+
+	d.Logger.Bodyf("Promoted download to %s", d.CachePath)
+	return nil
+}
+
+func (d DependencyCache) observeCacheHit(dependency BuildpackDependency) {
+	if d.Observer != nil {
+		d.Observer.OnCacheHit(dependency)
+	}
+}
+
+// observedDownload notifies the Observer, if any, of a cache miss, and of the size and duration of the download once
+// downloadFirst succeeds.  It returns the SHA256 of the download.
+func (d DependencyCache) observedDownload(dependency BuildpackDependency, candidates []*url.URL, destination string, expected string, validators *httpValidators, mods ...RequestModifierFunc) (string, error) {
+	if d.Observer == nil {
+		return d.downloadFirst(candidates, destination, expected, validators, mods...)
+	}
+
+	d.Observer.OnCacheMiss(dependency)
+
+	start := time.Now()
+	checksum, err := d.downloadFirst(candidates, destination, expected, validators, mods...)
+	if err != nil {
+		return "", err
+	}
+	duration := time.Since(start)
+
+	info, err := os.Stat(destination)
+	if err != nil {
+		return "", fmt.Errorf("unable to stat %s\n%w", destination, err)
+	}
+
+	d.Observer.OnDownloadComplete(dependency, info.Size(), duration)
+	return checksum, nil
+}
+
+// downloadFirst downloads from each of the candidate URIs in order until one succeeds and, if expected is set, is
+// verified against that checksum.  It returns the SHA256 of the download, or the error of the last candidate if none
+// succeed.  A candidate that responds 304 Not Modified ends the search with errNotModified.
+func (d DependencyCache) downloadFirst(candidates []*url.URL, destination string, expected string, validators *httpValidators, mods ...RequestModifierFunc) (string, error) {
+	var (
+		actual string
+		err    error
+	)
+
+	for _, u := range candidates {
+		d.Logger.Bodyf("%s from %s", color.YellowString("Downloading"), u.Redacted())
+		if actual, err = d.download(u, destination, validators, mods...); errors.Is(err, errNotModified) {
+			return "", err
+		} else if err != nil {
+			err = fmt.Errorf("unable to download %s\n%w", u.Redacted(), err)
+		} else if expected != "" {
+			d.Logger.Body("Verifying checksum")
+			err = verifyChecksum(destination, actual, expected)
+		}
+
+		if err == nil {
+			if len(candidates) > 1 {
+				d.Logger.Bodyf("Downloaded from %s", u.Redacted())
+			}
+			return actual, nil
+		}
+
+		if len(candidates) > 1 {
+			d.Logger.Bodyf("%s from %s, trying next location", color.YellowString("Download failed"), u.Redacted())
+			d.Logger.Debugf("%s", err)
+		}
+	}
+
+	return "", err
+}
+
+// download downloads url to destination and returns the SHA256 of the content, computed as it is written.  If
+// validators is set, it receives the ETag and Last-Modified headers of an HTTP response.
+func (d DependencyCache) download(url *url.URL, destination string, validators *httpValidators, mods ...RequestModifierFunc) (string, error) {
+	if url.Scheme == "file" {
+		return d.downloadFile(url.Path, destination, mods...)
+	}
+
+	return d.downloadHttp(url, destination, validators, mods...)
+}
+
+func (d DependencyCache) downloadFile(source string, destination string, mods ...RequestModifierFunc) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return "", fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+	}
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("unable to open destination file %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	input, err := os.Open(source)
+	if os.IsNotExist(err) {
+		return "", NotCachedError{Path: source}
+	} else if err != nil {
+		return "", fmt.Errorf("unable to open source file %s\n%w", source, err)
+	}
+	defer input.Close()
+
+	if info, err := input.Stat(); err == nil && d.MaxArtifactBytes > 0 && info.Size() > d.MaxArtifactBytes {
+		return "", ArtifactTooLargeError{URI: source, Limit: d.MaxArtifactBytes, Size: info.Size()}
+	}
+
+	s := sha256.New()
+	if err := d.copyArtifact(io.MultiWriter(out, s), input, source, destination); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(s.Sum(nil)), nil
+}
+
+// httpClient returns HTTPClient if set, otherwise a client configured with HttpClientTimeouts.  Unless
+// VerifyLocalhostTLS is set, the client for localhost does not verify TLS certificates.
+func (d DependencyCache) httpClient(url *url.URL) *http.Client {
+	if d.HTTPClient != nil {
+		return d.HTTPClient
+	}
+
+	if !d.VerifyLocalhostTLS && (strings.EqualFold(url.Hostname(), "localhost") || strings.EqualFold(url.Hostname(), "127.0.0.1")) {
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Dial: func(network string, address string) (net.Conn, error) {
+				if d.DialerNetwork != "" {
+					network = d.DialerNetwork
+				}
+
+				return (&net.Dialer{
+					Timeout:   d.HttpClientTimeouts.DialerTimeout,
+					KeepAlive: d.HttpClientTimeouts.DialerKeepAlive,
+				}).Dial(network, address)
+			},
+			TLSHandshakeTimeout:   d.HttpClientTimeouts.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: d.HttpClientTimeouts.ResponseHeaderTimeout,
+			ExpectContinueTimeout: d.HttpClientTimeouts.ExpectContinueTimeout,
+			Proxy:                 http.ProxyFromEnvironment,
+		},
+	}
+}
+
+// get requests url, returning errNotModified or a DownloadStatusError if the response is not successful.  The body of
+// a successful response must be closed by the caller.
+func (d DependencyCache) get(url *url.URL, mods ...RequestModifierFunc) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new GET request for %s\n%w", url.Redacted(), err)
+	}
+
+	if d.UserAgent != "" {
+		req.Header.Set("User-Agent", d.UserAgent)
+	}
+
+	if len(d.AcceptEncodings) > 0 {
+		for _, e := range d.AcceptEncodings {
+			if !supportedContentEncoding(e) {
+				return nil, fmt.Errorf("unsupported content encoding %s", e)
+			}
+		}
+		req.Header.Set("Accept-Encoding", strings.Join(d.AcceptEncodings, ", "))
+	}
+
+	for _, m := range mods {
+		req, err = m(req)
+		if err != nil {
+			return nil, fmt.Errorf("unable to modify request\n%w", err)
+		}
+	}
+
+	resp, err := d.httpClient(url).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request %s\n%w", url.Redacted(), err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, errNotModified
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, DownloadStatusError{URI: url.Redacted(), StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+func (d DependencyCache) downloadHttp(url *url.URL, destination string, validators *httpValidators, mods ...RequestModifierFunc) (string, error) {
+	resp, err := d.get(url, mods...)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if d.MaxArtifactBytes > 0 && resp.ContentLength > d.MaxArtifactBytes {
+		return "", ArtifactTooLargeError{URI: url.Redacted(), Limit: d.MaxArtifactBytes, Size: resp.ContentLength}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return "", fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(destination), err)
+	}
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("unable to open file %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	// response digests are of the content as it is sent, before it is decoded
+	digests := responseDigests(resp.Header)
+	var body io.Reader = resp.Body
+	hashes := map[string]hash.Hash{}
+	for algorithm := range digests {
+		h := newDigestHash(algorithm)
+		hashes[algorithm] = h
+		body = io.TeeReader(body, h)
+	}
+
+	body, err = d.decode(body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return "", fmt.Errorf("unable to decode %s\n%w", url.Redacted(), err)
+	}
+
+	s := sha256.New()
+	if err := d.copyArtifact(io.MultiWriter(out, s), body, url.Redacted(), destination); err != nil {
+		return "", err
+	}
+
+	for algorithm, h := range hashes {
+		if actual := base64.StdEncoding.EncodeToString(h.Sum(nil)); actual != digests[algorithm] {
+			d.Logger.Headerf("%s %s digest %s of %s does not match %s from response headers",
+				color.New(color.FgYellow, color.Bold).Sprint("Warning:"), algorithm, actual, url.Redacted(), digests[algorithm])
+		}
+	}
+
+	if validators != nil {
+		validators.ETag = resp.Header.Get("ETag")
+		validators.LastModified = resp.Header.Get("Last-Modified")
+	}
+
+	return hex.EncodeToString(s.Sum(nil)), nil
+}
+
+// decode returns a reader of body decoded from the content encoding of a response.  Responses are only decoded if
+// AcceptEncodings is set, as otherwise the HTTP client has already decoded them.
+func (d DependencyCache) decode(body io.Reader, encoding string) (io.Reader, error) {
+	if len(d.AcceptEncodings) == 0 {
+		return body, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		// deflate should be zlib wrapped, but some servers send raw deflate
+		b := bufio.NewReader(body)
+		if h, err := b.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			return zlib.NewReader(b)
+		}
+		return flate.NewReader(b), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", encoding)
+	}
+}
+
+// supportedContentEncoding indicates whether a response with the content encoding can be decoded.
+func supportedContentEncoding(encoding string) bool {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "deflate", "identity":
+		return true
+	default:
+		return false
+	}
+}
+
+// copyArtifact copies the artifact from source to destination, returning an ArtifactTooLargeError as soon as more than
+// MaxArtifactBytes have been copied.
+func (d DependencyCache) copyArtifact(out io.Writer, in io.Reader, source string, destination string) error {
+	if d.MaxArtifactBytes > 0 {
+		in = io.LimitReader(in, d.MaxArtifactBytes+1)
+	}
+
+	n, err := io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("unable to copy from %s to %s\n%w", source, destination, err)
+	}
+
+	if d.MaxArtifactBytes > 0 && n > d.MaxArtifactBytes {
+		return ArtifactTooLargeError{URI: source, Limit: d.MaxArtifactBytes}
+	}
+
+	return nil
+}
+
+// errNotModified is returned when a conditional request is answered with 304 Not Modified.
+var errNotModified = errors.New("not modified")
+
+// httpValidators are the SHA256 of the latest download of a dependency without a SHA256, and the HTTP cache
+// validators, if any, that the server returned for it.
+type httpValidators struct {
+	ETag         string `toml:"etag,omitempty"`
+	LastModified string `toml:"last-modified,omitempty"`
+	SHA256       string `toml:"sha256"`
+}
+
+// conditional is a RequestModifierFunc that makes the request conditional on the validators.
+func (h httpValidators) conditional(request *http.Request) (*http.Request, error) {
+	if h.ETag != "" {
+		request.Header.Set("If-None-Match", h.ETag)
+	}
+	if h.LastModified != "" {
+		request.Header.Set("If-Modified-Since", h.LastModified)
+	}
+
+	return request, nil
+}
+
+// validatorsKey returns the key under which the validators of uri are stored.
+func validatorsKey(uri string) string {
+	s := sha256.Sum256([]byte(uri))
+	return hex.EncodeToString(s[:])
+}
+
+// responseDigests returns the base64 encoded digests, keyed by lowercase algorithm, declared by the Digest and
+// Content-MD5 response headers.  Only the md5, sha-256, and sha-512 algorithms are returned.
+func responseDigests(header http.Header) map[string]string {
+	digests := map[string]string{}
+
+	for _, value := range header.Values("Digest") {
+		for _, d := range strings.Split(value, ",") {
+			algorithm, digest, ok := strings.Cut(strings.TrimSpace(d), "=")
+			if !ok {
+				continue
+			}
+
+			algorithm = strings.ToLower(algorithm)
+			if newDigestHash(algorithm) != nil {
+				digests[algorithm] = digest
+			}
+		}
+	}
+
+	if value := header.Get("Content-MD5"); value != "" {
+		digests["md5"] = strings.TrimSpace(value)
+	}
+
+	return digests
+}
+
+// artifactName returns the name an artifact is stored under, which is the Filename of the dependency if it is set and
+// otherwise the last element of path.
+func artifactName(dependency BuildpackDependency, path string) string {
+	if dependency.Filename != "" {
+		return filepath.Base(dependency.Filename)
+	}
+
+	return filepath.Base(path)
+}
+
+// splitChecksum splits a BuildpackDependency.SHA256, which is either a bare SHA256 or in the form algorithm:hex, into
+// its algorithm and hex value.
+func splitChecksum(checksum string) (string, string) {
+	if algorithm, value, ok := strings.Cut(checksum, ":"); ok {
+		return strings.ToLower(algorithm), value
+	}
+
+	return "sha256", checksum
+}
+
+// ChecksumDirectory returns the name under which the artifact of a dependency with the given checksum is cached.  A
+// SHA256 is used as its bare hex value and other algorithms as <algorithm>-<hex>, so that the name is a valid path
+// element on all platforms.
+func ChecksumDirectory(checksum string) string {
+	algorithm, value := splitChecksum(checksum)
+	if algorithm == "sha256" {
+		return value
+	}
+
+	return fmt.Sprintf("%s-%s", algorithm, value)
+}
+
+// formatChecksum formats the hex value of a checksum in the same form as expected.
+func formatChecksum(expected string, value string) string {
+	if algorithm, _, ok := strings.Cut(expected, ":"); ok {
+		return fmt.Sprintf("%s:%s", algorithm, value)
+	}
+
+	return value
+}
+
+// newChecksumHash returns a new hash for the algorithm of checksum.
+func newChecksumHash(checksum string) (hash.Hash, error) {
+	switch algorithm, _ := splitChecksum(checksum); algorithm {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha384":
+		return sha512.New384(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm %s", algorithm)
+	}
+}
+
+// verifyChecksum returns a ChecksumMismatchError if the file at path does not match the expected checksum.  If the
+// SHA256 of the file is already known, the file is only read again for other algorithms.
+func verifyChecksum(path string, sha256 string, expected string) error {
+	algorithm, value := splitChecksum(expected)
+
+	actual := sha256
+	if actual == "" || algorithm != "sha256" {
+		h, err := newChecksumHash(expected)
+		if err != nil {
+			return err
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open %s\n%w", path, err)
+		}
+		defer in.Close()
+
+		if _, err := io.Copy(h, in); err != nil {
+			return fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+		actual = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if !strings.EqualFold(actual, value) {
+		return ChecksumMismatchError{Path: path, Expected: expected, Actual: formatChecksum(expected, actual)}
+	}
+
+	return nil
+}
+
+func newDigestHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "md5":
+		return md5.New()
+	case "sha-256":
+		return sha256.New()
+	case "sha-512":
+		return sha512.New()
+	default:
+		return nil
+	}
+}
+
+func (d DependencyCache) setDependencyMirror(urlD *url.URL, mirror string) {
+	if mirror != "" {
+		d.Logger.Bodyf("%s Download URIs will be overridden.", color.GreenString("Dependency mirror found."))
+		mirrorArgs := parseMirror(mirror)
+		urlOverride, err := url.ParseRequestURI(mirrorArgs["mirror"])
+
+		if strings.ToLower(urlOverride.Scheme) == "https" || strings.ToLower(urlOverride.Scheme) == "file" {
+			urlD.Scheme = urlOverride.Scheme
+			urlD.User = urlOverride.User
+			root := strings.Replace(urlOverride.Path, "{originalHost}", urlD.Hostname(), 1)
+			path := strings.Replace(urlD.Path, mirrorArgs["skip-path"], "", 1)
+			if preserve, _ := strconv.ParseBool(mirrorArgs["preserve-path"]); preserve {
+				root = fmt.Sprintf("%s/%s", strings.TrimSuffix(root, "/"), urlD.Hostname())
+			}
+			urlD.Path = root + path
+			urlD.Host = urlOverride.Host
+		} else {
+			d.Logger.Debugf("Dependency mirror URI is invalid: %s\n%w", mirror, err)
 			d.Logger.Bodyf("%s is ignored. Have you used one of the supported schemes https:// or file://?", color.YellowString("Invalid dependency mirror"))
 		}
 	}
 }
 
+// splitMirrors splits a raw mirror string containing a comma or whitespace separated list of mirrors into the raw
+// mirror strings of each.  A new mirror begins at each URI or mirror= argument, while any other arguments apply to the
+// preceding mirror.
+func splitMirrors(raw string) []string {
+	var (
+		mirrors []string
+		current []string
+	)
+
+	for _, field := range strings.Fields(raw) {
+		for _, arg := range strings.Split(field, ",") {
+			if arg == "" {
+				continue
+			}
+
+			isMirror := strings.HasPrefix(arg, "mirror=") || (!strings.Contains(arg, "=") &&
+				(strings.HasPrefix(arg, "https") || strings.HasPrefix(arg, "file")))
+			if isMirror && len(current) > 0 {
+				mirrors = append(mirrors, strings.Join(current, ","))
+				current = nil
+			}
+
+			current = append(current, arg)
+		}
+	}
+
+	if len(current) > 0 {
+		mirrors = append(mirrors, strings.Join(current, ","))
+	}
+
+	return mirrors
+}
+
 // Parses a raw mirror string into a map of arguments.
 func parseMirror(mirror string) map[string]string {
 
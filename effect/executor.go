@@ -17,8 +17,12 @@
 package effect
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os/exec"
+	"time"
 )
 
 // Execution is information about a command to run.
@@ -44,6 +48,63 @@ type Execution struct {
 
 	// Stderr is the Writer to use for stderr.
 	Stderr io.Writer
+
+	// Context is the context the command is run in.  The command is killed if the context is done before it completes.
+	// Defaults to context.Background().
+	Context context.Context
+
+	// Timeout is the maximum time the command is allowed to run before it is killed.  Defaults to no timeout.
+	Timeout time.Duration
+}
+
+// WaitDelay is how long an Execution waits for its output to be closed once the command has been killed, for example
+// by a process that it started and that could not be killed with it.
+const WaitDelay = 10 * time.Second
+
+// command creates an exec.Cmd bound to the context and timeout of the Execution.  When the context is done, the command
+// and any processes that it started are killed.  The returned function must be called to release resources once the
+// command has completed.
+func (e Execution) command() (*exec.Cmd, context.Context, context.CancelFunc) {
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if e.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+	}
+
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	cmd.WaitDelay = WaitDelay
+
+	if e.Dir != "" {
+		cmd.Dir = e.Dir
+	}
+
+	if len(e.Env) > 0 {
+		cmd.Env = e.Env
+	}
+
+	cmd.Stdin = e.Stdin
+
+	return cmd, ctx, cancel
+}
+
+// contextError returns a descriptive error if ctx is done, otherwise err.
+func (e Execution) contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s did not complete within %s\n%w", e.Command, e.Timeout, err)
+	}
+
+	return fmt.Errorf("%s was cancelled\n%w", e.Command, err)
 }
 
 //go:generate mockery -name Executor -case=underscore
@@ -55,23 +116,28 @@ type Executor interface {
 	Execute(execution Execution) error
 }
 
+// ExecuteWithOutput executes the command described in the Execution with executor and returns the text written to
+// stdout and stderr.  Any Stdout and Stderr configured on the Execution are replaced.  Executors that run the command
+// with a TTY write both streams to stdout.
+func ExecuteWithOutput(executor Executor, execution Execution) (string, string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	execution.Stdout = stdout
+	execution.Stderr = stderr
+
+	err := executor.Execute(execution)
+	return stdout.String(), stderr.String(), err
+}
+
 // CommandExecutor is an implementation of Executor that uses exec.Command and runs the command without a TTY.
 type CommandExecutor struct{}
 
 func (CommandExecutor) Execute(execution Execution) error {
-	cmd := exec.Command(execution.Command, execution.Args...)
-
-	if execution.Dir != "" {
-		cmd.Dir = execution.Dir
-	}
-
-	if len(execution.Env) > 0 {
-		cmd.Env = execution.Env
-	}
+	cmd, ctx, cancel := execution.command()
+	defer cancel()
 
-	cmd.Stdin = execution.Stdin
+	newProcessGroup(cmd)
 	cmd.Stdout = execution.Stdout
 	cmd.Stderr = execution.Stderr
 
-	return cmd.Run()
+	return execution.contextError(ctx, cmd.Run())
 }
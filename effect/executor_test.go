@@ -0,0 +1,135 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package effect_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+func testExecutor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	it.Before(func() {
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	it("returns the output of the command", func() {
+		out, errOut, err := effect.ExecuteWithOutput(effect.CommandExecutor{}, effect.Execution{
+			Command: "sh",
+			Args:    []string{"-c", "echo test-stdout; echo test-stderr >&2; exit 1"},
+			Stdout:  stdout,
+		})
+
+		Expect(err).To(MatchError("exit status 1"))
+		Expect(out).To(Equal("test-stdout\n"))
+		Expect(errOut).To(Equal("test-stderr\n"))
+		Expect(stdout.String()).To(BeEmpty())
+	})
+
+	for name, executor := range map[string]effect.Executor{
+		"CommandExecutor": effect.CommandExecutor{},
+		"TTYExecutor":     effect.TTYExecutor{},
+	} {
+		executor := executor
+
+		context(name, func() {
+			it("runs the command", func() {
+				Expect(executor.Execute(effect.Execution{
+					Command: "sh",
+					Args:    []string{"-c", "echo test-output"},
+					Stdout:  stdout,
+					Stderr:  stderr,
+				})).To(Succeed())
+
+				Expect(stdout.String()).To(ContainSubstring("test-output"))
+			})
+
+			it("kills the command after the timeout", func() {
+				start := time.Now()
+
+				err := executor.Execute(effect.Execution{
+					Command: "sh",
+					Args:    []string{"-c", "sleep 30"},
+					Stdout:  stdout,
+					Stderr:  stderr,
+					Timeout: 100 * time.Millisecond,
+				})
+
+				Expect(err).To(MatchError(ContainSubstring("sh did not complete within 100ms")))
+				Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			})
+
+			it("kills processes started by the command after the timeout", func() {
+				start := time.Now()
+
+				err := executor.Execute(effect.Execution{
+					Command: "sh",
+					Args:    []string{"-c", "sleep 30 & sleep 30"},
+					Stdout:  stdout,
+					Stderr:  stderr,
+					Timeout: 100 * time.Millisecond,
+				})
+
+				Expect(err).To(MatchError(ContainSubstring("sh did not complete within 100ms")))
+				Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			})
+
+			it("kills the command when the context is cancelled", func() {
+				ctx, cancel := gocontext.WithCancel(gocontext.Background())
+				time.AfterFunc(100*time.Millisecond, cancel)
+
+				err := executor.Execute(effect.Execution{
+					Command: "sh",
+					Args:    []string{"-c", "sleep 30"},
+					Stdout:  stdout,
+					Stderr:  stderr,
+					Context: ctx,
+				})
+
+				Expect(err).To(MatchError(ContainSubstring("sh was cancelled")))
+			})
+
+			it("returns the error of a failed command", func() {
+				err := executor.Execute(effect.Execution{
+					Command: "sh",
+					Args:    []string{"-c", "exit 1"},
+					Stdout:  stdout,
+					Stderr:  stderr,
+				})
+
+				Expect(err).To(MatchError("exit status 1"))
+			})
+		})
+	}
+}
@@ -33,18 +33,10 @@ import (
 type TTYExecutor struct{}
 
 func (t TTYExecutor) Execute(execution Execution) error {
-	cmd := exec.Command(execution.Command, execution.Args...)
-
-	if execution.Dir != "" {
-		cmd.Dir = execution.Dir
-	}
-
-	if len(execution.Env) > 0 {
-		cmd.Env = execution.Env
-	}
-
-	cmd.Stdin = execution.Stdin
+	cmd, ctx, cancel := execution.command()
+	defer cancel()
 
+	// the PTY starts the command in a new session, which is also a new process group
 	f, err := pty.Start(cmd)
 	if err != nil {
 		return fmt.Errorf("unable to start PTY\n%w", err)
@@ -57,7 +49,7 @@ func (t TTYExecutor) Execute(execution Execution) error {
 		}
 	}
 
-	return cmd.Wait()
+	return execution.contextError(ctx, cmd.Wait())
 }
 
 func (TTYExecutor) isEIO(err error) bool {
@@ -79,3 +71,16 @@ func NewExecutor() Executor {
 	// 	return CommandExecutor{}
 	// }
 }
+
+// newProcessGroup configures cmd to start in a new process group, so that the processes it starts can be killed with it.
+func newProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killProcessGroup kills the process group led by cmd.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -19,7 +19,19 @@
 
 package effect
 
+import (
+	"os/exec"
+)
+
 // NewExecutor creates a new Executor.
 func NewExecutor() Executor {
 	return CommandExecutor{}
 }
+
+// newProcessGroup does nothing, as process groups are not supported.
+func newProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills the process of cmd.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FakeDependencyCache is a test double for DependencyCache that returns content added by a test instead of
+// downloading dependencies, and records every dependency that is requested.  Content is added for a dependency ID and
+// version, and the checksum of a dependency is never verified.
+type FakeDependencyCache struct {
+
+	// Path is the directory the content is written to.
+	Path string
+
+	fetched []BuildpackDependency
+	mutex   sync.Mutex
+}
+
+// NewFakeDependencyCache creates a new FakeDependencyCache that writes content to path, typically t.TempDir().
+func NewFakeDependencyCache(path string) *FakeDependencyCache {
+	return &FakeDependencyCache{Path: path}
+}
+
+// Add adds the content of dependency.  If the dependency has a SHA256, the content is also written to the cache layout
+// that DependencyCache expects, so that it is returned by DependencyCache.
+func (f *FakeDependencyCache) Add(dependency BuildpackDependency, content []byte) error {
+	file := f.file(dependency)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	if dependency.SHA256 == "" {
+		return nil
+	}
+
+	file = filepath.Join(f.Path, "cache", ChecksumDirectory(dependency.SHA256), artifactName(dependency, dependency.URI))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to make directory %s\n%w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(dependency); err != nil {
+		return fmt.Errorf("unable to encode metadata for %s\n%w", dependency.ID, err)
+	}
+
+	file = filepath.Join(f.Path, "cache", fmt.Sprintf("%s.toml", ChecksumDirectory(dependency.SHA256)))
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+// Artifact returns the content added for the dependency.  If no content has been added, a NotCachedError is returned.
+func (f *FakeDependencyCache) Artifact(dependency BuildpackDependency, _ ...RequestModifierFunc) (*os.File, error) {
+	f.record(dependency)
+
+	file := f.file(dependency)
+	in, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, NotCachedError{Path: file}
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+
+	return in, nil
+}
+
+// DependencyCache returns a DependencyCache that returns the added content, for code that requires a DependencyCache
+// such as DependencyLayerContributor.  Dependencies that have not been added, or that have no SHA256, fail with a
+// NotCachedError instead of being downloaded, and every dependency requested is recorded in Fetched.
+func (f *FakeDependencyCache) DependencyCache() DependencyCache {
+	return DependencyCache{
+		CachePath:    filepath.Join(f.Path, "cache"),
+		DownloadPath: filepath.Join(f.Path, "downloads"),
+		Observer:     fakeDependencyCacheObserver{f},
+		URIRewriter: func(original *url.URL) (*url.URL, error) {
+			return &url.URL{Scheme: "file", Path: filepath.Join(f.Path, "missing", filepath.Base(original.Path))}, nil
+		},
+	}
+}
+
+// Fetched returns the dependencies that have been requested, in order.
+func (f *FakeDependencyCache) Fetched() []BuildpackDependency {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]BuildpackDependency{}, f.fetched...)
+}
+
+func (f *FakeDependencyCache) file(dependency BuildpackDependency) string {
+	return filepath.Join(f.Path, "artifacts", dependency.ID, dependency.Version, artifactName(dependency, dependency.URI))
+}
+
+func (f *FakeDependencyCache) record(dependency BuildpackDependency) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.fetched = append(f.fetched, dependency)
+}
+
+// fakeDependencyCacheObserver records the dependencies requested from the DependencyCache of a FakeDependencyCache.
+type fakeDependencyCacheObserver struct {
+	cache *FakeDependencyCache
+}
+
+func (f fakeDependencyCacheObserver) OnCacheHit(dependency BuildpackDependency) {
+	f.cache.record(dependency)
+}
+
+func (f fakeDependencyCacheObserver) OnCacheMiss(dependency BuildpackDependency) {
+	f.cache.record(dependency)
+}
+
+func (f fakeDependencyCacheObserver) OnDownloadComplete(BuildpackDependency, int64, time.Duration) {}
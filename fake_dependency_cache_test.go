@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+func testFakeDependencyCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cache      *libpak.FakeDependencyCache
+		dependency libpak.BuildpackDependency
+		other      libpak.BuildpackDependency
+	)
+
+	it.Before(func() {
+		cache = libpak.NewFakeDependencyCache(t.TempDir())
+
+		dependency = libpak.BuildpackDependency{
+			ID:      "test-id",
+			Name:    "test-name",
+			Version: "1.1.1",
+			URI:     "https://localhost/test-path",
+			SHA256:  "test-sha256",
+			Stacks:  []string{"test-stack"},
+		}
+
+		other = dependency
+		other.ID = "other-id"
+
+		Expect(cache.Add(dependency, []byte("test-fixture"))).To(Succeed())
+	})
+
+	it("returns added content", func() {
+		a, err := cache.Artifact(dependency)
+		Expect(err).NotTo(HaveOccurred())
+		defer a.Close()
+
+		Expect(io.ReadAll(a)).To(Equal([]byte("test-fixture")))
+		Expect(cache.Fetched()).To(Equal([]libpak.BuildpackDependency{dependency}))
+	})
+
+	it("returns NotCachedError for content that has not been added", func() {
+		_, err := cache.Artifact(other)
+		Expect(err).To(MatchError(libpak.NotCachedError{}))
+		Expect(cache.Fetched()).To(Equal([]libpak.BuildpackDependency{other}))
+	})
+
+	context("DependencyCache", func() {
+		it("returns added content", func() {
+			d := cache.DependencyCache()
+
+			a, err := d.Artifact(dependency)
+			Expect(err).NotTo(HaveOccurred())
+			defer a.Close()
+
+			Expect(io.ReadAll(a)).To(Equal([]byte("test-fixture")))
+			Expect(cache.Fetched()).To(Equal([]libpak.BuildpackDependency{dependency}))
+		})
+
+		it("returns NotCachedError for content that has not been added", func() {
+			d := cache.DependencyCache()
+
+			_, err := d.Artifact(other)
+			Expect(err).To(MatchError(libpak.NotCachedError{}))
+			Expect(cache.Fetched()).To(Equal([]libpak.BuildpackDependency{other}))
+		})
+
+		it("contributes a dependency layer", func() {
+			layers := libcnb.Layers{Path: t.TempDir()}
+			layer, err := layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			dlc := libpak.NewDependencyLayerContributor(dependency, cache.DependencyCache(), libcnb.LayerTypes{})
+
+			_, err = dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+
+				Expect(io.ReadAll(artifact)).To(Equal([]byte("test-fixture")))
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cache.Fetched()).To(Equal([]libpak.BuildpackDependency{dependency}))
+		})
+	})
+}
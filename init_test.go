@@ -28,11 +28,15 @@ func TestUnit(t *testing.T) {
 	suite("Build", testBuild)
 	suite("Buildpack", testBuildpack)
 	suite("BuildpackPlan", testBuildpackPlan)
+	suite("BuildpackSBOM", testBuildpackSBOM)
 	suite("Detect", testDetect)
 	suite("DependencyCache", testDependencyCache)
+	suite("FakeDependencyCache", testFakeDependencyCache)
 	suite("Formatter", testFormatter)
 	suite("Layer", testLayer)
 	suite("Main", testMain)
+	suite("Netrc", testNetrc)
 	suite("Stack", testStack)
+	suite("VersionMatcher", testVersionMatcher)
 	suite.Run(t)
 }
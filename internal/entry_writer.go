@@ -21,11 +21,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const ModeExecutable = 0100
 
-type EntryWriter struct{}
+type EntryWriter struct {
+
+	// ModificationTime is the time to set as the access and modification time of every file written, so that the
+	// files are reproducible.  Symbolic links keep the time they are created at.  If zero, times are not changed.
+	ModificationTime time.Time
+}
 
 func (e EntryWriter) Write(source string, destination string) error {
 	p := filepath.Dir(destination)
@@ -74,5 +80,17 @@ func (e EntryWriter) Write(source string, destination string) error {
 		return fmt.Errorf("unable to copy %s to %s\n%w", source, destination, err)
 	}
 
+	if e.ModificationTime.IsZero() {
+		return nil
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("unable to close destination file %s\n%w", destination, err)
+	}
+
+	if err := os.Chtimes(destination, e.ModificationTime, e.ModificationTime); err != nil {
+		return fmt.Errorf("unable to set times of %s\n%w", destination, err)
+	}
+
 	return nil
 }
@@ -19,6 +19,7 @@ package internal_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
@@ -77,6 +78,25 @@ func testEntryWriter(t *testing.T, context spec.G, it spec.S) {
 		Expect(s.Mode()&0100 == 0100).To(BeTrue())
 	})
 
+	it("does not change times by default", func() {
+		Expect(writer.Write(source, destination)).To(Succeed())
+
+		s, err := os.Stat(destination)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.ModTime()).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	it("sets modification time", func() {
+		writer.ModificationTime = time.Unix(1577836800, 0)
+
+		Expect(writer.Write(source, destination)).To(Succeed())
+		Expect(os.ReadFile(destination)).To(Equal([]byte("test-value")))
+
+		s, err := os.Stat(destination)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.ModTime().Equal(time.Unix(1577836800, 0))).To(BeTrue())
+	})
+
 	context("symlink", func() {
 		var (
 			symlinkSource string
@@ -86,3 +86,58 @@ func (w EnvironmentWriter) Write(path string, environment map[string]string) err
 
 	return nil
 }
+
+// Append writes value to the <name>.append file in path and delimiter to the <name>.delim file.  If <name>.append
+// already exists, value is appended to its contents separated by delimiter.
+func (w EnvironmentWriter) Append(path string, name string, delimiter string, value string) error {
+	return w.writeDelimited(path, name, "append", delimiter, func(existing string) string {
+		return existing + delimiter + value
+	}, value)
+}
+
+// Prepend writes value to the <name>.prepend file in path and delimiter to the <name>.delim file.  If <name>.prepend
+// already exists, value is prepended to its contents separated by delimiter.
+func (w EnvironmentWriter) Prepend(path string, name string, delimiter string, value string) error {
+	return w.writeDelimited(path, name, "prepend", delimiter, func(existing string) string {
+		return value + delimiter + existing
+	}, value)
+}
+
+func (w EnvironmentWriter) writeDelimited(path string, name string, suffix string, delimiter string,
+	combine func(existing string) string, value string) error {
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("unable to mkdir %s\n%w", path, err)
+	}
+
+	d := filepath.Join(path, fmt.Sprintf("%s.delim", name))
+	if b, err := os.ReadFile(d); err == nil {
+		if string(b) != delimiter {
+			return fmt.Errorf("unable to write %s with delimiter %q, %s already uses delimiter %q", name, delimiter, d, string(b))
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read %s\n%w", d, err)
+	}
+
+	f := filepath.Join(path, fmt.Sprintf("%s.%s", name, suffix))
+	if b, err := os.ReadFile(f); err == nil {
+		if len(b) > 0 {
+			value = combine(string(b))
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read %s\n%w", f, err)
+	}
+
+	base := filepath.Base(path)
+	w.logger.Bodyf("Writing %s/%s.%s", base, name, suffix)
+
+	if err := os.WriteFile(f, []byte(value), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", f, err)
+	}
+
+	if err := os.WriteFile(d, []byte(delimiter), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", d, err)
+	}
+
+	return nil
+}
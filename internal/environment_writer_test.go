@@ -78,6 +78,40 @@ func testEnvironmentWriter(t *testing.T, context spec.G, it spec.S) {
 		Expect(path).NotTo(BeAnExistingFile())
 	})
 
+	context("Append", func() {
+		it("writes append and delim files", func() {
+			Expect(writer.Append(path, "PATH", ":", "/test/bin")).To(Succeed())
+
+			Expect(os.ReadFile(filepath.Join(path, "PATH.append"))).To(Equal([]byte("/test/bin")))
+			Expect(os.ReadFile(filepath.Join(path, "PATH.delim"))).To(Equal([]byte(":")))
+		})
+
+		it("appends to existing file", func() {
+			Expect(writer.Append(path, "PATH", ":", "/test/bin")).To(Succeed())
+			Expect(writer.Append(path, "PATH", ":", "/other/bin")).To(Succeed())
+
+			Expect(os.ReadFile(filepath.Join(path, "PATH.append"))).To(Equal([]byte("/test/bin:/other/bin")))
+			Expect(os.ReadFile(filepath.Join(path, "PATH.delim"))).To(Equal([]byte(":")))
+		})
+
+		it("returns error with conflicting delimiter", func() {
+			Expect(writer.Append(path, "PATH", ":", "/test/bin")).To(Succeed())
+
+			Expect(writer.Append(path, "PATH", ";", "/other/bin")).
+				To(MatchError(ContainSubstring(`already uses delimiter ":"`)))
+		})
+	})
+
+	context("Prepend", func() {
+		it("prepends to existing file", func() {
+			Expect(writer.Prepend(path, "LD_LIBRARY_PATH", ":", "/test/lib")).To(Succeed())
+			Expect(writer.Prepend(path, "LD_LIBRARY_PATH", ":", "/other/lib")).To(Succeed())
+
+			Expect(os.ReadFile(filepath.Join(path, "LD_LIBRARY_PATH.prepend"))).To(Equal([]byte("/other/lib:/test/lib")))
+			Expect(os.ReadFile(filepath.Join(path, "LD_LIBRARY_PATH.delim"))).To(Equal([]byte(":")))
+		})
+	})
+
 	context("Logging", func() {
 		var (
 			b *bytes.Buffer
@@ -2,12 +2,50 @@ package internal
 
 import (
 	"bytes"
+	"errors"
 
 	"github.com/BurntSushi/toml"
 )
 
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Marshal encodes v as TOML.  The output is stable: the keys of maps are written in sorted order and the fields of
+// structs in the order they are declared, so encoding equal values always produces identical bytes.
 func Marshal(v interface{}) ([]byte, error) {
 	buf := new(bytes.Buffer)
 	err := toml.NewEncoder(buf).Encode(v)
 	return buf.Bytes(), err
 }
+
+// NormalizeTOML returns content without a leading UTF-8 byte order mark and with CRLF line endings replaced by LF, so
+// that files written on Windows can be decoded and edited line by line.
+func NormalizeTOML(content []byte) []byte {
+	content = bytes.TrimPrefix(content, utf8BOM)
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// Unmarshal decodes the normalized content into v.  If the content is not valid TOML, the error includes the line and
+// column of the problem.
+func Unmarshal(content []byte, v interface{}) error {
+	err := toml.Unmarshal(NormalizeTOML(content), v)
+
+	var parseError toml.ParseError
+	if errors.As(err, &parseError) {
+		return TOMLParseError{parseError}
+	}
+
+	return err
+}
+
+// TOMLParseError is a toml.ParseError that describes the line and column of the problem.
+type TOMLParseError struct {
+	toml.ParseError
+}
+
+func (t TOMLParseError) Error() string {
+	return t.ErrorWithPosition()
+}
+
+func (t TOMLParseError) Unwrap() error {
+	return t.ParseError
+}
@@ -1,6 +1,7 @@
 package internal_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/BurntSushi/toml"
@@ -49,4 +50,28 @@ func testTOMLMarshal(t *testing.T, context spec.G, it spec.S) {
 		Expect(someData).To(Equal(output))
 		Expect(someData.APointer).To(BeNil())
 	})
+
+	context("Unmarshal", func() {
+		it("decodes content with a byte order mark", func() {
+			var v map[string]interface{}
+			Expect(internal.Unmarshal([]byte("\xEF\xBB\xBFsome-field = \"some-value\"\n"), &v)).To(Succeed())
+			Expect(v).To(Equal(map[string]interface{}{"some-field": "some-value"}))
+		})
+
+		it("decodes content with CRLF line endings", func() {
+			var v map[string]interface{}
+			Expect(internal.Unmarshal([]byte("[table]\r\nsome-field = \"\"\"\r\nsome-value\"\"\"\r\n"), &v)).To(Succeed())
+			Expect(v).To(Equal(map[string]interface{}{"table": map[string]interface{}{"some-field": "some-value"}}))
+		})
+
+		it("describes the position of invalid content", func() {
+			var v map[string]interface{}
+			err := internal.Unmarshal([]byte("\xEF\xBB\xBFsome-field = \"some-value\"\r\nother-field = invalid\r\n"), &v)
+
+			Expect(err).To(MatchError(ContainSubstring("At line 2")))
+
+			var parseError toml.ParseError
+			Expect(errors.As(err, &parseError)).To(BeTrue())
+		})
+	})
 }
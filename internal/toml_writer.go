@@ -23,7 +23,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/libcnb"
 	"github.com/heroku/color"
 
@@ -60,7 +59,7 @@ func NewTOMLWriter(options ...TOMLWriterOption) TOMLWriter {
 }
 
 // Write creates the path's parent directories, and creates a new file or truncates an existing file and then marshals
-// the value to the file.
+// the value to the file.  The value is marshaled with Marshal, so map keys are written in a stable, sorted order.
 func (t TOMLWriter) Write(path string, value interface{}) error {
 	if value == nil {
 		return nil
@@ -130,18 +129,21 @@ func (t TOMLWriter) Write(path string, value interface{}) error {
 		}
 	}
 
+	b, err := Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s\n%w", path, err)
+	}
+
 	d := filepath.Dir(path)
 	if err := os.MkdirAll(d, 0755); err != nil {
 		return fmt.Errorf("unable to mkdir %s\n%w", d, err)
 	}
 
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("unable to open file %s\n%w", path, err)
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", path, err)
 	}
-	defer file.Close()
 
-	return toml.NewEncoder(file).Encode(value)
+	return nil
 }
 
 func (TOMLWriter) maxTypeLength(processes []libcnb.Process) int {
@@ -58,6 +58,50 @@ some-field = "some-value"
 other-field = "other-value"`))
 	})
 
+	it("writes keys in sorted order", func() {
+		err := tomlWriter.Write(path, map[string]interface{}{
+			"some-field":  "some-value",
+			"other-field": "other-value",
+			"metadata": map[string]interface{}{
+				"zeta":  "zeta-value",
+				"alpha": "alpha-value",
+				"mu":    "mu-value",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.ReadFile(path)).To(Equal([]byte(`other-field = "other-value"
+some-field = "some-value"
+
+[metadata]
+  alpha = "alpha-value"
+  mu = "mu-value"
+  zeta = "zeta-value"
+`)))
+	})
+
+	it("writes identical files for equal values", func() {
+		value := map[string]interface{}{}
+		for i := 0; i < 20; i++ {
+			value[fmt.Sprintf("field-%d", i)] = map[string]interface{}{"value": i}
+		}
+
+		Expect(tomlWriter.Write(path, value)).To(Succeed())
+		expected, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			Expect(tomlWriter.Write(path, value)).To(Succeed())
+			Expect(os.ReadFile(path)).To(Equal(expected))
+		}
+	})
+
+	it("does not write a file for a value that cannot be marshaled", func() {
+		Expect(tomlWriter.Write(path, map[string]interface{}{"some-field": func() {}})).
+			To(MatchError(ContainSubstring(fmt.Sprintf("unable to marshal %s", path))))
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
 	context("Logging", func() {
 		var (
 			b *bytes.Buffer
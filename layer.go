@@ -17,11 +17,16 @@
 package libpak
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -29,6 +34,7 @@ import (
 
 	"github.com/buildpacks/libcnb"
 
+	"github.com/paketo-buildpacks/libpak/crush"
 	"github.com/paketo-buildpacks/libpak/internal"
 	"github.com/paketo-buildpacks/libpak/sbom"
 	"github.com/paketo-buildpacks/libpak/sherpa"
@@ -51,6 +57,14 @@ type LayerContributor struct {
 
 	// ExpectedTypes indicates the types that should be set on the layer.
 	ExpectedTypes libcnb.LayerTypes
+
+	// MetadataComparer is an optional function used to compare the expected and actual layer metadata.  Both maps have
+	// their dependency deprecation dates normalized before being compared.  Defaults to reflect.DeepEqual.
+	MetadataComparer func(expected, actual map[string]interface{}) (bool, error)
+
+	// AlwaysRebuild indicates that the layer should never be reused, for example because it is derived from the
+	// application source.  The LayerFunc is always called, regardless of the existing layer metadata.
+	AlwaysRebuild bool
 }
 
 // NewLayerContributor creates a new instance.
@@ -62,6 +76,29 @@ func NewLayerContributor(name string, expectedMetadata interface{}, expectedType
 	}
 }
 
+// StableMetadataHash returns the SHA256 of v encoded as canonical TOML.  Map keys are sorted and structs are encoded as
+// the equivalent maps, so equal values always have the same hash.  The hash can be used as compact ExpectedMetadata in
+// place of v.
+func StableMetadataHash(v interface{}) (string, error) {
+	raw, err := internal.Marshal(map[string]interface{}{"value": v})
+	if err != nil {
+		return "", fmt.Errorf("unable to encode metadata\n%w", err)
+	}
+
+	var canonical map[string]interface{}
+	if err := toml.Unmarshal(raw, &canonical); err != nil {
+		return "", fmt.Errorf("unable to decode metadata\n%w", err)
+	}
+
+	raw, err = internal.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode canonical metadata\n%w", err)
+	}
+
+	s := sha256.Sum256(raw)
+	return hex.EncodeToString(s[:]), nil
+}
+
 // LayerFunc is a callback function that is invoked when a layer needs to be contributed.
 type LayerFunc func() (libcnb.Layer, error)
 
@@ -77,13 +114,14 @@ func (l *LayerContributor) Contribute(layer libcnb.Layer, f LayerFunc) (libcnb.L
 		return libcnb.Layer{}, fmt.Errorf("unable to check metadata\n%w", err)
 	}
 
-	if cached && layerRestored {
+	if cached && layerRestored && !l.AlwaysRebuild {
 		l.Logger.Headerf("%s: %s cached layer", color.BlueString(l.Name), color.GreenString("Reusing"))
 		layer.LayerTypes = l.ExpectedTypes
 		return layer, nil
 	}
 
 	if !layerRestored {
+		l.Logger.Debugf("Layer %s was not restored, its directory is missing or empty", layer.Path)
 		l.Logger.Headerf("%s: %s cached layer", color.BlueString(l.Name), color.RedString("Reloading"))
 	} else {
 		l.Logger.Headerf("%s: %s to layer", color.BlueString(l.Name), color.YellowString("Contributing"))
@@ -123,9 +161,61 @@ func (l *LayerContributor) checkIfMetadataMatches(layer libcnb.Layer) (map[strin
 	if err != nil {
 		return map[string]interface{}{}, false, fmt.Errorf("unable to compare metadata\n%w", err)
 	}
+
+	if !match {
+		if key, e, a, ok := metadataDifference("", expected, layer.Metadata); ok {
+			l.Logger.Debugf("Metadata mismatch at %s -> expected: %+v, actual: %+v", key, e, a)
+		} else {
+			l.Logger.Debugf("Metadata mismatch reported by metadata comparer")
+		}
+	}
+
 	return expected, match, nil
 }
 
+// metadataDifference returns the first key, in sorted order, at which the expected and actual metadata differ along
+// with the differing values.  Nested keys are joined with a period.
+func metadataDifference(prefix string, expected map[string]interface{}, actual map[string]interface{}) (string, interface{}, interface{}, bool) {
+	keys := map[string]bool{}
+	for k := range expected {
+		keys[k] = true
+	}
+	for k := range actual {
+		keys[k] = true
+	}
+
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		key := k
+		if prefix != "" {
+			key = fmt.Sprintf("%s.%s", prefix, k)
+		}
+
+		e, eOk := expected[k]
+		a, aOk := actual[k]
+
+		eMap, eIsMap := e.(map[string]interface{})
+		aMap, aIsMap := a.(map[string]interface{})
+		if eIsMap && aIsMap {
+			if key, e, a, ok := metadataDifference(key, eMap, aMap); ok {
+				return key, e, a, true
+			}
+			continue
+		}
+
+		if eOk != aOk || !reflect.DeepEqual(e, a) {
+			return key, e, a, true
+		}
+	}
+
+	return "", nil, nil, false
+}
+
 func (l *LayerContributor) Equals(expectedM map[string]interface{}, layerM map[string]interface{}) (bool, error) {
 	// TODO Do we want the Equals method to modify the underlying maps? Else we need to make a copy here.
 
@@ -137,6 +227,10 @@ func (l *LayerContributor) Equals(expectedM map[string]interface{}, layerM map[s
 		return false, fmt.Errorf("%w (actual layer)", err)
 	}
 
+	if l.MetadataComparer != nil {
+		return l.MetadataComparer(expectedM, layerM)
+	}
+
 	return reflect.DeepEqual(expectedM, layerM), nil
 }
 
@@ -236,6 +330,9 @@ type DependencyLayerContributor struct {
 	// DependencyCache is the cache to use to get the dependency.
 	DependencyCache DependencyCache
 
+	// ArtifactResolver is used to get the dependency instead of DependencyCache, if set.
+	ArtifactResolver ArtifactResolver
+
 	// ExpectedTypes indicates the types that should be set on the layer.
 	ExpectedTypes libcnb.LayerTypes
 
@@ -247,6 +344,17 @@ type DependencyLayerContributor struct {
 
 	// RequestModifierFuncs is an optional Request Modifier to use when downloading the dependency.
 	RequestModifierFuncs []RequestModifierFunc
+
+	// SBOMSource is the file the dependency is declared in, used as the location in the SBOM.  Defaults to
+	// buildpack.toml.  Extensions should use extension.toml.
+	SBOMSource string
+
+	// SBOMFormats are the formats the SBOM of the layer is written in.  Defaults to libcnb.SyftJSON.
+	SBOMFormats []libcnb.SBOMFormat
+
+	// BeforeContribute is an optional function that is called after the dependency has been verified and its SBOM
+	// written, but before the DependencyLayerFunc is called.  An error aborts the contribution.
+	BeforeContribute func(layer *libcnb.Layer, artifact *os.File) error
 }
 
 // NewDependencyLayer returns a new DependencyLayerContributor for the given BuildpackDependency and a BOMEntry describing the layer contents.
@@ -289,29 +397,108 @@ func (d *DependencyLayerContributor) Contribute(layer libcnb.Layer, f Dependency
 	lc.Logger = d.Logger
 
 	return lc.Contribute(layer, func() (libcnb.Layer, error) {
-		artifact, err := d.DependencyCache.Artifact(d.Dependency, d.RequestModifierFuncs...)
+		var resolver ArtifactResolver = &d.DependencyCache
+		if d.ArtifactResolver != nil {
+			resolver = d.ArtifactResolver
+		}
+
+		artifact, err := resolver.Artifact(d.Dependency, d.RequestModifierFuncs...)
 		if err != nil {
 			d.Logger.Debugf("fetching dependency %s failed\n%w", d.Dependency.Name, err)
 			return libcnb.Layer{}, fmt.Errorf("unable to get dependency %s. see DEBUG log level", d.Dependency.Name)
 		}
 		defer artifact.Close()
 
-		sbomArtifact, err := d.Dependency.AsSyftArtifact()
+		source := d.SBOMSource
+		if source == "" {
+			source = "buildpack.toml"
+		}
+
+		sbomArtifact, err := d.Dependency.AsSyftArtifactFrom(source)
 		if err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to get SBOM artifact %s\n%w", d.Dependency.ID, err)
 		}
 
-		sbomPath := layer.SBOMPath(libcnb.SyftJSON)
-		dep := sbom.NewSyftDependency(layer.Path, []sbom.SyftArtifact{sbomArtifact})
-		d.Logger.Debugf("Writing Syft SBOM at %s: %+v", sbomPath, dep)
-		if err := dep.WriteTo(sbomPath); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to write SBOM\n%w", err)
+		sbomArtifacts := []sbom.SyftArtifact{sbomArtifact}
+		sourceArtifact, ok, err := d.Dependency.AsSourceSyftArtifactFrom(source)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to get source SBOM artifact %s\n%w", d.Dependency.ID, err)
+		}
+		if ok {
+			sbomArtifacts = append(sbomArtifacts, sourceArtifact)
+		}
+
+		if err := writeLayerSBOM(layer, sbom.NewSyftDependency(layer.Path, sbomArtifacts), d.SBOMFormats, d.Logger); err != nil {
+			return libcnb.Layer{}, err
+		}
+
+		if d.BeforeContribute != nil {
+			if err := d.BeforeContribute(&layer, artifact); err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to prepare contribution of %s\n%w", d.Dependency.Name, err)
+			}
+
+			if _, err := artifact.Seek(0, io.SeekStart); err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to rewind %s\n%w", artifact.Name(), err)
+			}
 		}
 
 		return f(artifact)
 	})
 }
 
+// writeLayerSBOM writes dep as the SBOM of layer in each of formats, or as libcnb.SyftJSON if there are none.
+func writeLayerSBOM(layer libcnb.Layer, dep sbom.SyftDependency, formats []libcnb.SBOMFormat, logger bard.Logger) error {
+	if len(formats) == 0 {
+		formats = []libcnb.SBOMFormat{libcnb.SyftJSON}
+	}
+
+	for _, f := range formats {
+		path := layer.SBOMPath(f)
+		logger.Debugf("Writing SBOM at %s: %+v", path, dep)
+		if err := dep.WriteFormatTo(path, f); err != nil {
+			return fmt.Errorf("unable to write SBOM\n%w", err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveAndContribute resolves the dependency with the given id and version from the buildpack's metadata and
+// contributes it to a layer named after the dependency, calling f with the layer and the downloaded artifact.  A
+// NoValidDependenciesError from resolution is returned unchanged so that it can be checked with IsNoValidDependencies.
+func ResolveAndContribute(context libcnb.BuildContext, id string, version string, types libcnb.LayerTypes,
+	logger bard.Logger, f func(layer libcnb.Layer, artifact *os.File) (libcnb.Layer, error)) (libcnb.Layer, error) {
+
+	dr, err := NewDependencyResolver(context)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create dependency resolver\n%w", err)
+	}
+	dr.Logger = &logger
+
+	dependency, err := dr.Resolve(id, version)
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	dc, err := NewDependencyCache(context)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create dependency cache\n%w", err)
+	}
+	dc.Logger = logger
+
+	dlc := NewDependencyLayerContributor(dependency, dc, types)
+	dlc.Logger = logger
+
+	layer, err := context.Layers.Layer(dlc.LayerName())
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer %s\n%w", dlc.LayerName(), err)
+	}
+
+	return dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+		return f(layer, artifact)
+	})
+}
+
 // LayerName returns the conventional name of the layer for this contributor
 func (d *DependencyLayerContributor) LayerName() string {
 	return d.Dependency.ID
@@ -322,6 +509,353 @@ func (d *DependencyLayerContributor) Name() string {
 	return fmt.Sprintf("%s %s", d.Dependency.Name, d.Dependency.Version)
 }
 
+// DependencyLayerExtractor is a libcnb.LayerContributor that extracts a BuildpackDependency archive into a layer.
+type DependencyLayerExtractor struct {
+
+	// LayerContributor is the contributor used to fetch the dependency and write its SBOM.
+	LayerContributor DependencyLayerContributor
+
+	// StripComponents is the number of leading path components to remove from the archive entries.
+	StripComponents int
+}
+
+// NewDependencyLayerExtractor returns a new DependencyLayerExtractor for the given BuildpackDependency.
+func NewDependencyLayerExtractor(dependency BuildpackDependency, cache DependencyCache, types libcnb.LayerTypes,
+	logger bard.Logger, stripComponents int) DependencyLayerExtractor {
+
+	lc := NewDependencyLayerContributor(dependency, cache, types)
+	lc.Logger = logger
+
+	return DependencyLayerExtractor{
+		LayerContributor: lc,
+		StripComponents:  stripComponents,
+	}
+}
+
+// Contribute downloads and extracts the dependency into the layer.
+func (d DependencyLayerExtractor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	return d.LayerContributor.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+		d.LayerContributor.Logger.Bodyf("Expanding to %s", layer.Path)
+
+		if err := crush.Extract(artifact, layer.Path, d.StripComponents); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to expand %s\n%w", d.LayerContributor.Dependency.Name, err)
+		}
+
+		return layer, nil
+	})
+}
+
+// Name returns the conventional name of the layer for this contributor
+func (d DependencyLayerExtractor) Name() string {
+	return d.LayerContributor.LayerName()
+}
+
+// MultiDependencyLayerContributor is a libcnb.LayerContributor that extracts several related BuildpackDependency
+// archives, such as a runtime and its modules, into a single layer.  The layer is reused as long as the SHA256 of all
+// the dependencies is unchanged.
+type MultiDependencyLayerContributor struct {
+
+	// LayerName is the name of the layer.
+	LayerName string
+
+	// Dependencies are the dependencies to extract, in order.  Later dependencies overwrite files of earlier ones.
+	Dependencies []BuildpackDependency
+
+	// DependencyCache is the cache to use to get the dependencies.
+	DependencyCache DependencyCache
+
+	// ArtifactResolver is used to get the dependencies instead of DependencyCache, if set.
+	ArtifactResolver ArtifactResolver
+
+	// ExpectedTypes indicates the types that should be set on the layer.
+	ExpectedTypes libcnb.LayerTypes
+
+	// Logger is the logger to use.
+	Logger bard.Logger
+
+	// RequestModifierFuncs is an optional Request Modifier to use when downloading the dependencies.
+	RequestModifierFuncs []RequestModifierFunc
+
+	// SBOMSource is the file the dependencies are declared in, used as the location in the SBOM.  Defaults to
+	// buildpack.toml.
+	SBOMSource string
+
+	// SBOMFormats are the formats the SBOM of the layer is written in.  Defaults to libcnb.SyftJSON.
+	SBOMFormats []libcnb.SBOMFormat
+
+	// StripComponents is the number of leading path components to remove from the archive entries.
+	StripComponents int
+}
+
+// NewMultiDependencyLayerContributor returns a new MultiDependencyLayerContributor that extracts dependencies into the
+// layer named name.
+func NewMultiDependencyLayerContributor(name string, dependencies []BuildpackDependency, cache DependencyCache,
+	types libcnb.LayerTypes, logger bard.Logger, stripComponents int) MultiDependencyLayerContributor {
+
+	return MultiDependencyLayerContributor{
+		LayerName:       name,
+		Dependencies:    dependencies,
+		DependencyCache: cache,
+		ExpectedTypes:   types,
+		Logger:          logger,
+		StripComponents: stripComponents,
+	}
+}
+
+// Contribute downloads and extracts each of the dependencies into the layer, and writes an SBOM with an artifact for
+// each.
+func (m MultiDependencyLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	lc := NewLayerContributor(m.LayerName, map[string]interface{}{"dependencies-sha256": m.sha256()}, m.ExpectedTypes)
+	lc.Logger = m.Logger
+
+	return lc.Contribute(layer, func() (libcnb.Layer, error) {
+		source := m.SBOMSource
+		if source == "" {
+			source = "buildpack.toml"
+		}
+
+		var sbomArtifacts []sbom.SyftArtifact
+		for _, d := range m.Dependencies {
+			if err := m.extract(d, layer); err != nil {
+				return libcnb.Layer{}, err
+			}
+
+			sbomArtifact, err := d.AsSyftArtifactFrom(source)
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to get SBOM artifact %s\n%w", d.ID, err)
+			}
+			sbomArtifacts = append(sbomArtifacts, sbomArtifact)
+
+			sourceArtifact, ok, err := d.AsSourceSyftArtifactFrom(source)
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to get source SBOM artifact %s\n%w", d.ID, err)
+			}
+			if ok {
+				sbomArtifacts = append(sbomArtifacts, sourceArtifact)
+			}
+		}
+
+		if err := writeLayerSBOM(layer, sbom.NewSyftDependency(layer.Path, sbomArtifacts), m.SBOMFormats, m.Logger); err != nil {
+			return libcnb.Layer{}, err
+		}
+
+		return layer, nil
+	})
+}
+
+// Name returns the name of the layer.
+func (m MultiDependencyLayerContributor) Name() string {
+	return m.LayerName
+}
+
+// extract downloads and extracts dependency into the layer.
+func (m MultiDependencyLayerContributor) extract(dependency BuildpackDependency, layer libcnb.Layer) error {
+	var resolver ArtifactResolver = &m.DependencyCache
+	if m.ArtifactResolver != nil {
+		resolver = m.ArtifactResolver
+	}
+
+	artifact, err := resolver.Artifact(dependency, m.RequestModifierFuncs...)
+	if err != nil {
+		m.Logger.Debugf("fetching dependency %s failed\n%w", dependency.Name, err)
+		return fmt.Errorf("unable to get dependency %s. see DEBUG log level", dependency.Name)
+	}
+	defer artifact.Close()
+
+	m.Logger.Bodyf("Expanding %s %s to %s", dependency.Name, dependency.Version, layer.Path)
+	if err := crush.Extract(artifact, layer.Path, m.StripComponents); err != nil {
+		return fmt.Errorf("unable to expand %s\n%w", dependency.Name, err)
+	}
+
+	return nil
+}
+
+// sha256 returns the SHA256 of the ids and checksums of all the dependencies, in order.
+func (m MultiDependencyLayerContributor) sha256() string {
+	h := sha256.New()
+	for _, d := range m.Dependencies {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s\x00", d.ID, d.Version, d.SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// StaticFileContributor is a libcnb.LayerContributor that copies a set of static files into a layer.  The layer is
+// reused as long as the SHA256 of the files is unchanged.
+type StaticFileContributor struct {
+
+	// LayerName is the name of the layer.
+	LayerName string
+
+	// Files are the files to copy into the layer.
+	Files fs.FS
+
+	// ExpectedTypes indicates the types that should be set on the layer.
+	ExpectedTypes libcnb.LayerTypes
+
+	// Logger is the logger to use.
+	Logger bard.Logger
+}
+
+// NewStaticFileContributor returns a new StaticFileContributor that copies files, often an embed.FS, into the layer
+// named name.
+func NewStaticFileContributor(name string, files fs.FS, types libcnb.LayerTypes, logger bard.Logger) StaticFileContributor {
+	return StaticFileContributor{
+		LayerName:     name,
+		Files:         files,
+		ExpectedTypes: types,
+		Logger:        logger,
+	}
+}
+
+// Contribute copies the files into the layer.
+func (s StaticFileContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	checksum, err := s.sha256()
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to compute SHA256 of %s files\n%w", s.LayerName, err)
+	}
+
+	lc := NewLayerContributor(s.LayerName, map[string]interface{}{"files-sha256": checksum}, s.ExpectedTypes)
+	lc.Logger = s.Logger
+
+	return lc.Contribute(layer, func() (libcnb.Layer, error) {
+		err := fs.WalkDir(s.Files, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			destination := filepath.Join(layer.Path, filepath.FromSlash(path))
+			if d.IsDir() {
+				if err := os.MkdirAll(destination, 0755); err != nil {
+					return fmt.Errorf("unable to create %s\n%w", destination, err)
+				}
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("unable to stat %s\n%w", path, err)
+			}
+
+			b, err := fs.ReadFile(s.Files, path)
+			if err != nil {
+				return fmt.Errorf("unable to read %s\n%w", path, err)
+			}
+
+			s.Logger.Bodyf("Writing %s", destination)
+			if err := os.WriteFile(destination, b, info.Mode().Perm()|0644); err != nil {
+				return fmt.Errorf("unable to write %s\n%w", destination, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to copy files into %s\n%w", layer.Path, err)
+		}
+
+		return layer, nil
+	})
+}
+
+// Name returns the name of the layer.
+func (s StaticFileContributor) Name() string {
+	return s.LayerName
+}
+
+// sha256 returns the SHA256 of the paths and contents of all the files.
+func (s StaticFileContributor) sha256() (string, error) {
+	h := sha256.New()
+
+	err := fs.WalkDir(s.Files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		b, err := fs.ReadFile(s.Files, path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00", path, len(b))
+		_, _ = h.Write(b)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// AppSubdirContributor is a libcnb.LayerContributor that copies a directory of the application, such as pre-compiled
+// assets, into a layer.  The layer is reused as long as the SHA256 of the files in the directory is unchanged.
+type AppSubdirContributor struct {
+
+	// ApplicationPath is the path of the application.  If empty, the working directory is used, which the lifecycle
+	// sets to the application directory during build.
+	ApplicationPath string
+
+	// Path is the path of the directory to copy, relative to ApplicationPath.
+	Path string
+
+	// LayerName is the name of the layer.
+	LayerName string
+
+	// ExpectedTypes indicates the types that should be set on the layer.
+	ExpectedTypes libcnb.LayerTypes
+
+	// Logger is the logger to use.
+	Logger bard.Logger
+}
+
+// NewAppSubdirContributor returns a new AppSubdirContributor that copies the directory path, relative to the
+// application, into a layer.  The layer is named after path, for example "public-assets" for public/assets.
+func NewAppSubdirContributor(path string, types libcnb.LayerTypes, logger bard.Logger) AppSubdirContributor {
+	name := strings.ReplaceAll(filepath.ToSlash(filepath.Clean(path)), "/", "-")
+	if name == "." {
+		name = "application"
+	}
+
+	return AppSubdirContributor{
+		Path:          path,
+		LayerName:     name,
+		ExpectedTypes: types,
+		Logger:        logger,
+	}
+}
+
+// Contribute copies the directory into the layer.
+func (a AppSubdirContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	if !filepath.IsLocal(a.Path) {
+		return libcnb.Layer{}, fmt.Errorf("path %s must be relative to the application and within it", a.Path)
+	}
+
+	application := a.ApplicationPath
+	if application == "" {
+		var err error
+		if application, err = os.Getwd(); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to get working directory\n%w", err)
+		}
+	}
+
+	source := filepath.Join(application, a.Path)
+	if ok, err := sherpa.DirExists(source); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to check %s\n%w", source, err)
+	} else if !ok {
+		return libcnb.Layer{}, fmt.Errorf("unable to find directory %s in application", a.Path)
+	}
+
+	return NewStaticFileContributor(a.LayerName, os.DirFS(source), a.ExpectedTypes, a.Logger).Contribute(layer)
+}
+
+// Name returns the name of the layer.
+func (a AppSubdirContributor) Name() string {
+	return a.LayerName
+}
+
 // HelperLayerContributor is a helper for implementing a libcnb.LayerContributor for a buildpack helper application in
 // order to get consistent logging and avoidance.
 type HelperLayerContributor struct {
@@ -337,6 +871,13 @@ type HelperLayerContributor struct {
 
 	// Names are the names of the helpers to create
 	Names []string
+
+	// Sources are optional paths to distinct helper applications, keyed by helper name.  Helpers without a source link
+	// to the helper application at Path.
+	Sources map[string]string
+
+	// SBOMFormats are the formats the SBOM of the layer is written in.  Defaults to libcnb.SyftJSON.
+	SBOMFormats []libcnb.SBOMFormat
 }
 
 // NewHelperLayer returns a new HelperLayerContributor and a BOMEntry describing the layer contents.
@@ -366,6 +907,25 @@ func NewHelperLayerContributor(buildpack libcnb.Buildpack, names ...string) Help
 	}
 }
 
+// NewHelperLayerContributorWithSources returns a new HelperLayerContributor where each helper links to its own helper
+// application.  Sources maps each helper name to the path of its application, relative to the buildpack.
+func NewHelperLayerContributorWithSources(buildpack libcnb.Buildpack, sources map[string]string) HelperLayerContributor {
+	var names []string
+	s := map[string]string{}
+	for name, source := range sources {
+		names = append(names, name)
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(buildpack.Path, source)
+		}
+		s[name] = source
+	}
+	sort.Strings(names)
+
+	h := NewHelperLayerContributor(buildpack, names...)
+	h.Sources = s
+	return h
+}
+
 // Name returns the conventional name of the layer for this contributor
 func (h HelperLayerContributor) Name() string {
 	return filepath.Base(h.Path)
@@ -374,6 +934,9 @@ func (h HelperLayerContributor) Name() string {
 // Contribute is the function to call whe implementing your libcnb.LayerContributor.
 func (h HelperLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	expected := map[string]interface{}{"buildpackInfo": h.BuildpackInfo, "helperNames": h.Names}
+	if len(h.Sources) > 0 {
+		expected["helperSources"] = h.Sources
+	}
 	lc := NewLayerContributor("Launch Helper", expected, libcnb.LayerTypes{
 		Launch: true,
 	})
@@ -381,18 +944,16 @@ func (h HelperLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, er
 	lc.Logger = h.Logger
 
 	return lc.Contribute(layer, func() (libcnb.Layer, error) {
-		in, err := os.Open(h.Path)
-		if err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to open %s\n%w", h.Path, err)
-		}
-		defer in.Close()
-
-		out := filepath.Join(layer.Path, "helper")
-		if err := sherpa.CopyFile(in, out); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to copy %s to %s", h.Path, out)
-		}
-
+		copied := map[string]bool{}
 		for _, n := range h.Names {
+			source, out := h.helperPaths(layer, n)
+			if !copied[out] {
+				if err := copyHelper(source, out); err != nil {
+					return libcnb.Layer{}, err
+				}
+				copied[out] = true
+			}
+
 			link := layer.Exec.FilePath(n)
 			h.Logger.Bodyf("Creating %s", link)
 
@@ -411,17 +972,41 @@ func (h HelperLayerContributor) Contribute(layer libcnb.Layer) (libcnb.Layer, er
 			return libcnb.Layer{}, fmt.Errorf("unable to get SBOM artifact for helper\n%w", err)
 		}
 
-		sbomPath := layer.SBOMPath(libcnb.SyftJSON)
-		dep := sbom.NewSyftDependency(layer.Path, []sbom.SyftArtifact{sbomArtifact})
-		h.Logger.Debugf("Writing Syft SBOM at %s: %+v", sbomPath, dep)
-		if err := dep.WriteTo(sbomPath); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to write SBOM\n%w", err)
+		if err := writeLayerSBOM(layer, sbom.NewSyftDependency(layer.Path, []sbom.SyftArtifact{sbomArtifact}), h.SBOMFormats, h.Logger); err != nil {
+			return libcnb.Layer{}, err
 		}
 
 		return layer, nil
 	})
 }
 
+// helperPaths returns the path of the helper application for a helper name and the path it is copied to in the layer.
+func (h HelperLayerContributor) helperPaths(layer libcnb.Layer, name string) (string, string) {
+	if source, ok := h.Sources[name]; ok {
+		return source, filepath.Join(layer.Path, "helpers", name)
+	}
+
+	return h.Path, filepath.Join(layer.Path, "helper")
+}
+
+func copyHelper(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", source, err)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("unable to create %s\n%w", filepath.Dir(destination), err)
+	}
+
+	if err := sherpa.CopyFile(in, destination); err != nil {
+		return fmt.Errorf("unable to copy %s to %s", source, destination)
+	}
+
+	return nil
+}
+
 func (h HelperLayerContributor) AsSyftArtifact() (sbom.SyftArtifact, error) {
 	licenses := []string{}
 	for _, license := range h.BuildpackInfo.Licenses {
@@ -431,9 +1016,13 @@ func (h HelperLayerContributor) AsSyftArtifact() (sbom.SyftArtifact, error) {
 	locations := []sbom.SyftLocation{}
 	cpes := []string{}
 	for _, name := range h.Names {
-		locations = append(locations, sbom.SyftLocation{Path: name})
-		cpes = append(cpes, fmt.Sprintf("cpe:2.3:a:%s:%s:%s:*:*:*:*:*:*:*",
-			h.BuildpackInfo.ID, name, h.BuildpackInfo.Version))
+		location := name
+		if _, ok := h.Sources[name]; ok {
+			location = filepath.Join("helpers", name)
+		}
+
+		locations = append(locations, sbom.SyftLocation{Path: location})
+		cpes = append(cpes, buildpackCPE(h.BuildpackInfo, name))
 	}
 
 	artifact := sbom.SyftArtifact{
@@ -444,7 +1033,7 @@ func (h HelperLayerContributor) AsSyftArtifact() (sbom.SyftArtifact, error) {
 		Licenses:  licenses,
 		Locations: locations,
 		CPEs:      cpes,
-		PURL:      fmt.Sprintf("pkg:generic/%s@%s", h.BuildpackInfo.ID, h.BuildpackInfo.Version),
+		PURL:      buildpackPURL(h.BuildpackInfo),
 	}
 	var err error
 	artifact.ID, err = artifact.Hash()
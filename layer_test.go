@@ -17,11 +17,17 @@
 package libpak_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/buildpacks/libcnb"
@@ -31,6 +37,8 @@ import (
 
 	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/mocks"
+	"github.com/paketo-buildpacks/libpak/sbom"
 )
 
 func testLayer(t *testing.T, context spec.G, it spec.S) {
@@ -60,6 +68,9 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 		)
 
 		it.Before(func() {
+			lc.Logger = bard.Logger{}
+			lc.MetadataComparer = nil
+			lc.AlwaysRebuild = false
 			lc.ExpectedMetadata = map[string]interface{}{
 				"alpha": "test-alpha",
 				"bravo": map[string]interface{}{
@@ -95,6 +106,26 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			Expect(called).To(BeTrue())
 		})
 
+		it("logs the first mismatched metadata key", func() {
+			layer.Metadata = map[string]interface{}{
+				"alpha": "test-alpha",
+				"bravo": map[string]interface{}{
+					"bravo-1": "test-bravo-1",
+					"bravo-2": "test-bravo-3",
+				},
+			}
+
+			b := &bytes.Buffer{}
+			lc.Logger = bard.NewLoggerWithOptions(io.Discard, bard.WithDebug(b))
+
+			_, err := lc.Contribute(layer, func() (libcnb.Layer, error) {
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(b.String()).To(ContainSubstring("Metadata mismatch at bravo.bravo-2 -> expected: test-bravo-2, actual: test-bravo-3"))
+		})
+
 		context("reloads layers not restored", func() {
 			var called bool
 
@@ -199,6 +230,65 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			Expect(called).To(BeFalse())
 		})
 
+		it("calls function with matching metadata when always rebuilding", func() {
+			layer.Metadata = map[string]interface{}{
+				"alpha": "test-alpha",
+				"bravo": map[string]interface{}{
+					"bravo-1": "test-bravo-1",
+					"bravo-2": "test-bravo-2",
+				},
+			}
+			lc.AlwaysRebuild = true
+
+			var called bool
+
+			layer, err := lc.Contribute(layer, func() (libcnb.Layer, error) {
+				called = true
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(called).To(BeTrue())
+			Expect(layer.LayerTypes).To(Equal(lc.ExpectedTypes))
+		})
+
+		it("does not call function with metadata matching custom comparer", func() {
+			layer.Metadata = map[string]interface{}{
+				"alpha": "test-alpha",
+				"bravo": map[string]interface{}{
+					"bravo-1": "test-bravo-1",
+					"bravo-2": "test-bravo-2",
+				},
+				"timestamp": "test-timestamp",
+			}
+
+			lc.MetadataComparer = func(expected, actual map[string]interface{}) (bool, error) {
+				delete(actual, "timestamp")
+				return reflect.DeepEqual(expected, actual), nil
+			}
+
+			var called bool
+
+			_, err := lc.Contribute(layer, func() (libcnb.Layer, error) {
+				called = true
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(called).To(BeFalse())
+		})
+
+		it("returns error from custom comparer", func() {
+			lc.MetadataComparer = func(expected, actual map[string]interface{}) (bool, error) {
+				return false, fmt.Errorf("test-error")
+			}
+
+			_, err := lc.Contribute(layer, func() (libcnb.Layer, error) {
+				return layer, nil
+			})
+			Expect(err).To(MatchError(ContainSubstring("test-error")))
+		})
+
 		it("returns function error", func() {
 			_, err := lc.Contribute(layer, func() (libcnb.Layer, error) {
 				return libcnb.Layer{}, fmt.Errorf("test-error")
@@ -279,6 +369,43 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("StableMetadataHash", func() {
+		it("returns the same hash for equal values", func() {
+			type metadata struct {
+				Alpha string            `toml:"alpha"`
+				Bravo map[string]string `toml:"bravo"`
+			}
+
+			a, err := libpak.StableMetadataHash(metadata{Alpha: "test-alpha", Bravo: map[string]string{"bravo-1": "1", "bravo-2": "2"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			b, err := libpak.StableMetadataHash(map[string]interface{}{
+				"bravo": map[string]interface{}{"bravo-2": "2", "bravo-1": "1"},
+				"alpha": "test-alpha",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(a).To(Equal(b))
+			Expect(a).To(HaveLen(64))
+		})
+
+		it("returns a different hash for different values", func() {
+			a, err := libpak.StableMetadataHash(map[string]interface{}{"alpha": "test-alpha"})
+			Expect(err).NotTo(HaveOccurred())
+
+			b, err := libpak.StableMetadataHash(map[string]interface{}{"alpha": "other-alpha"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(a).NotTo(Equal(b))
+		})
+
+		it("hashes values that are not tables", func() {
+			a, err := libpak.StableMetadataHash("test-value")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).NotTo(BeEmpty())
+		})
+	})
+
 	context("NewDependencyLayer", func() {
 		var dep libpak.BuildpackDependency
 
@@ -413,6 +540,8 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			dlc.Dependency = dependency
 			dlc.DependencyCache.CachePath = layer.Path
 			dlc.DependencyCache.DownloadPath = layer.Path
+			dlc.BeforeContribute = nil
+			dlc.SBOMSource = ""
 		})
 
 		it.After(func() {
@@ -435,6 +564,124 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			Expect(called).To(BeTrue())
 		})
 
+		it("gets the dependency from the ArtifactResolver", func() {
+			artifact := filepath.Join(t.TempDir(), "test-path")
+			Expect(os.WriteFile(artifact, []byte("test-fixture"), 0644)).To(Succeed())
+			in, err := os.Open(artifact)
+			Expect(err).NotTo(HaveOccurred())
+
+			resolver := &mocks.ArtifactResolver{}
+			resolver.On("Artifact", dependency).Return(in, nil)
+			dlc.ArtifactResolver = resolver
+
+			_, err = dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				Expect(io.ReadAll(artifact)).To(Equal([]byte("test-fixture")))
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			resolver.AssertExpectations(t)
+		})
+
+		it("returns an error if the ArtifactResolver fails", func() {
+			resolver := &mocks.ArtifactResolver{}
+			resolver.On("Artifact", dependency).Return(nil, fmt.Errorf("test-error"))
+			dlc.ArtifactResolver = resolver
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				return layer, nil
+			})
+			Expect(err).To(MatchError("unable to get dependency test-name. see DEBUG log level"))
+		})
+
+		it("calls before contribute function before function", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			var calls []string
+
+			dlc.BeforeContribute = func(layer *libcnb.Layer, artifact *os.File) error {
+				Expect(layer.SBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+				Expect(io.ReadAll(artifact)).To(Equal([]byte("test-fixture")))
+
+				calls = append(calls, "before")
+				return nil
+			}
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+				Expect(io.ReadAll(artifact)).To(Equal([]byte("test-fixture")))
+
+				calls = append(calls, "contribute")
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(calls).To(Equal([]string{"before", "contribute"}))
+		})
+
+		it("does not call function when before contribute fails", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			dlc.BeforeContribute = func(layer *libcnb.Layer, artifact *os.File) error {
+				return fmt.Errorf("test-error")
+			}
+
+			var called bool
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+
+				called = true
+				return layer, nil
+			})
+			Expect(err).To(MatchError(ContainSubstring("test-error")))
+
+			Expect(called).To(BeFalse())
+		})
+
+		it("writes SBOM with SBOM source", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			dlc.SBOMSource = "extension.toml"
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))).To(ContainSubstring(`"Locations":[{"Path":"extension.toml"}]`))
+		})
+
+		it("writes SBOM in each format", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			dlc.SBOMFormats = []libcnb.SBOMFormat{libcnb.CycloneDXJSON, libcnb.SPDXJSON}
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.CycloneDXJSON))).To(ContainSubstring(`"bomFormat":"CycloneDX"`))
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.SPDXJSON))).To(ContainSubstring(`"spdxVersion":"SPDX-2.2"`))
+			Expect(layer.SBOMPath(libcnb.SyftJSON)).NotTo(BeAnExistingFile())
+		})
+
+		it("writes SBOM with dependency source", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			dlc.Dependency.Source = "test-source-uri"
+
+			_, err := dlc.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+				defer artifact.Close()
+				return layer, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))).To(ContainSubstring(`"Name":"test-name (source)"`))
+		})
+
 		it("modifies request", func() {
 			server.AppendHandlers(ghttp.CombineHandlers(
 				ghttp.VerifyHeaderKV("Test-Key", "test-value"),
@@ -837,6 +1084,348 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("ResolveAndContribute", func() {
+		var (
+			ctx    libcnb.BuildContext
+			server *ghttp.Server
+		)
+
+		it.Before(func() {
+			t.Setenv("BP_ARCH", "amd64")
+
+			RegisterTestingT(t)
+			server = ghttp.NewServer()
+
+			ctx = libcnb.BuildContext{
+				Buildpack: libcnb.Buildpack{
+					Path: t.TempDir(),
+					Metadata: map[string]interface{}{
+						"dependencies": []map[string]interface{}{
+							{
+								"id":      "test-id",
+								"name":    "test-name",
+								"version": "1.1.1",
+								"uri":     fmt.Sprintf("%s/test-path", server.URL()),
+								"sha256":  "576dd8416de5619ea001d9662291d62444d1292a38e96956bc4651c01f14bca1",
+								"stacks":  []interface{}{"test-stack"},
+							},
+						},
+					},
+				},
+				Layers:  libcnb.Layers{Path: layersDir},
+				StackID: "test-stack",
+			}
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("resolves and contributes dependency", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, "test-fixture"))
+
+			var called bool
+			layer, err := libpak.ResolveAndContribute(ctx, "test-id", "1.1.1", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard),
+				func(layer libcnb.Layer, artifact *os.File) (libcnb.Layer, error) {
+					defer artifact.Close()
+
+					called = true
+					Expect(io.ReadAll(artifact)).To(Equal([]byte("test-fixture")))
+					return layer, nil
+				})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(called).To(BeTrue())
+			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "test-id")))
+			Expect(layer.LayerTypes.Launch).To(BeTrue())
+		})
+
+		it("returns NoValidDependenciesError unchanged", func() {
+			_, err := libpak.ResolveAndContribute(ctx, "test-id", "2.0.0", libcnb.LayerTypes{}, bard.NewLogger(io.Discard),
+				func(layer libcnb.Layer, artifact *os.File) (libcnb.Layer, error) {
+					return layer, nil
+				})
+
+			Expect(libpak.IsNoValidDependencies(err)).To(BeTrue())
+		})
+	})
+
+	context("DependencyLayerExtractor", func() {
+		var (
+			archive []byte
+			server  *ghttp.Server
+		)
+
+		it.Before(func() {
+			var err error
+			archive, err = os.ReadFile(filepath.Join("crush", "testdata", "test-archive.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, archive))
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("extracts the dependency into the layer", func() {
+			s := sha256.Sum256(archive)
+
+			dependency := libpak.BuildpackDependency{
+				ID:      "test-id",
+				Name:    "test-name",
+				Version: "1.1.1",
+				URI:     fmt.Sprintf("%s/test-archive.tar.gz", server.URL()),
+				SHA256:  hex.EncodeToString(s[:]),
+				Stacks:  []string{"test-stack"},
+			}
+			cache := libpak.DependencyCache{CachePath: t.TempDir(), DownloadPath: t.TempDir()}
+
+			dle := libpak.NewDependencyLayerExtractor(dependency, cache, libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard), 1)
+			Expect(dle.Name()).To(Equal("test-id"))
+
+			layer, err := dle.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LayerTypes.Launch).To(BeTrue())
+			Expect(filepath.Join(layer.Path, "fileB.txt")).To(BeARegularFile())
+			Expect(filepath.Join(layer.Path, "fileC.txt")).To(BeARegularFile())
+			Expect(layer.SBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+		})
+	})
+
+	context("MultiDependencyLayerContributor", func() {
+		var (
+			archive      []byte
+			dependencies []libpak.BuildpackDependency
+			server       *ghttp.Server
+		)
+
+		it.Before(func() {
+			var err error
+			archive, err = os.ReadFile(filepath.Join("crush", "testdata", "test-archive.tar.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			s := sha256.Sum256(archive)
+
+			other, err := os.ReadFile(filepath.Join("crush", "testdata", "test-archive.zip"))
+			Expect(err).NotTo(HaveOccurred())
+			o := sha256.Sum256(other)
+
+			server = ghttp.NewServer()
+			server.RouteToHandler(http.MethodGet, "/test-archive.tar.gz", ghttp.RespondWith(http.StatusOK, archive))
+			server.RouteToHandler(http.MethodGet, "/test-archive.zip", ghttp.RespondWith(http.StatusOK, other))
+
+			dependencies = []libpak.BuildpackDependency{
+				{
+					ID:      "test-core",
+					Name:    "test-core-name",
+					Version: "1.1.1",
+					URI:     fmt.Sprintf("%s/test-archive.tar.gz", server.URL()),
+					SHA256:  hex.EncodeToString(s[:]),
+					Stacks:  []string{"test-stack"},
+				},
+				{
+					ID:      "test-module",
+					Name:    "test-module-name",
+					Version: "2.2.2",
+					URI:     fmt.Sprintf("%s/test-archive.zip", server.URL()),
+					SHA256:  hex.EncodeToString(o[:]),
+					Stacks:  []string{"test-stack"},
+				},
+			}
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("extracts all dependencies into the layer", func() {
+			cache := libpak.DependencyCache{CachePath: t.TempDir(), DownloadPath: t.TempDir()}
+
+			mdlc := libpak.NewMultiDependencyLayerContributor("test-name", dependencies, cache,
+				libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard), 1)
+			Expect(mdlc.Name()).To(Equal("test-name"))
+
+			layer, err := mdlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LayerTypes.Launch).To(BeTrue())
+			Expect(filepath.Join(layer.Path, "fileB.txt")).To(BeARegularFile())
+			Expect(filepath.Join(layer.Path, "fileC.txt")).To(BeARegularFile())
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+			b, err := os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("test-core-name"))
+			Expect(string(b)).To(ContainSubstring("test-module-name"))
+		})
+
+		it("has stable metadata that changes with any dependency", func() {
+			cache := libpak.DependencyCache{CachePath: t.TempDir(), DownloadPath: t.TempDir()}
+
+			mdlc := libpak.NewMultiDependencyLayerContributor("test-name", dependencies, cache,
+				libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard), 1)
+
+			layer, err := mdlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			checksum := layer.Metadata["dependencies-sha256"]
+			Expect(checksum).NotTo(BeEmpty())
+
+			layer.Metadata = map[string]interface{}{"dependencies-sha256": checksum}
+			layer, err = mdlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layer.Metadata["dependencies-sha256"]).To(Equal(checksum))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+			mdlc.Dependencies[1].Version = "2.2.3"
+			layer, err = mdlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layer.Metadata["dependencies-sha256"]).NotTo(Equal(checksum))
+		})
+	})
+
+	context("StaticFileContributor", func() {
+		var files fstest.MapFS
+
+		it.Before(func() {
+			files = fstest.MapFS{
+				"test-file":           &fstest.MapFile{Data: []byte("test-content"), Mode: 0444},
+				"test-dir/test-file":  &fstest.MapFile{Data: []byte("nested-content"), Mode: 0644},
+				"test-dir/test-exec":  &fstest.MapFile{Data: []byte("exec-content"), Mode: 0755},
+				"test-dir/other-file": &fstest.MapFile{Data: []byte("other-content")},
+			}
+		})
+
+		it("copies files into the layer", func() {
+			sfc := libpak.NewStaticFileContributor("test-name", files, libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			Expect(sfc.Name()).To(Equal("test-name"))
+
+			layer, err := sfc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LayerTypes.Launch).To(BeTrue())
+			Expect(os.ReadFile(filepath.Join(layer.Path, "test-file"))).To(Equal([]byte("test-content")))
+			Expect(os.ReadFile(filepath.Join(layer.Path, "test-dir", "test-file"))).To(Equal([]byte("nested-content")))
+			Expect(layer.Metadata).To(HaveKey("files-sha256"))
+
+			info, err := os.Stat(filepath.Join(layer.Path, "test-dir", "test-exec"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+		})
+
+		it("reuses layer when files are unchanged", func() {
+			sfc := libpak.NewStaticFileContributor("test-name", files, libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+
+			layer, err := sfc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Remove(filepath.Join(layer.Path, "test-file"))).To(Succeed())
+
+			layer, err = sfc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(layer.Path, "test-file")).NotTo(BeAnExistingFile())
+		})
+
+		it("contributes layer when files change", func() {
+			sfc := libpak.NewStaticFileContributor("test-name", files, libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+
+			layer, err := sfc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			files["test-file"] = &fstest.MapFile{Data: []byte("changed-content")}
+
+			layer, err = sfc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(filepath.Join(layer.Path, "test-file"))).To(Equal([]byte("changed-content")))
+		})
+	})
+
+	context("AppSubdirContributor", func() {
+		var application string
+
+		it.Before(func() {
+			application = t.TempDir()
+
+			Expect(os.MkdirAll(filepath.Join(application, "public", "assets", "css"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(application, "public", "assets", "app.js"), []byte("test-js"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(application, "public", "assets", "css", "app.css"), []byte("test-css"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(application, "other-file"), []byte("other-content"), 0644)).To(Succeed())
+		})
+
+		it("copies the directory into the layer", func() {
+			asc := libpak.NewAppSubdirContributor("public/assets", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			asc.ApplicationPath = application
+			Expect(asc.Name()).To(Equal("public-assets"))
+
+			layer, err := asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LayerTypes.Launch).To(BeTrue())
+			Expect(os.ReadFile(filepath.Join(layer.Path, "app.js"))).To(Equal([]byte("test-js")))
+			Expect(os.ReadFile(filepath.Join(layer.Path, "css", "app.css"))).To(Equal([]byte("test-css")))
+			Expect(filepath.Join(layer.Path, "other-file")).NotTo(BeAnExistingFile())
+			Expect(layer.Metadata).To(HaveKey("files-sha256"))
+		})
+
+		it("reuses layer when the directory is unchanged", func() {
+			asc := libpak.NewAppSubdirContributor("public/assets", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			asc.ApplicationPath = application
+
+			layer, err := asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Remove(filepath.Join(layer.Path, "app.js"))).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(application, "other-file"), []byte("changed-content"), 0644)).To(Succeed())
+
+			layer, err = asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(layer.Path, "app.js")).NotTo(BeAnExistingFile())
+		})
+
+		it("contributes layer when the directory changes", func() {
+			asc := libpak.NewAppSubdirContributor("public/assets", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			asc.ApplicationPath = application
+
+			layer, err := asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.WriteFile(filepath.Join(application, "public", "assets", "app.js"), []byte("changed-js"), 0644)).To(Succeed())
+
+			layer, err = asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(filepath.Join(layer.Path, "app.js"))).To(Equal([]byte("changed-js")))
+		})
+
+		it("uses the working directory as the application", func() {
+			wd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(application)).To(Succeed())
+			defer func() { Expect(os.Chdir(wd)).To(Succeed()) }()
+
+			asc := libpak.NewAppSubdirContributor("public/assets", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+
+			layer, err := asc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(filepath.Join(layer.Path, "app.js"))).To(Equal([]byte("test-js")))
+		})
+
+		it("fails when the directory does not exist", func() {
+			asc := libpak.NewAppSubdirContributor("public/missing", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			asc.ApplicationPath = application
+
+			_, err := asc.Contribute(layer)
+			Expect(err).To(MatchError("unable to find directory public/missing in application"))
+		})
+
+		it("fails when the path is outside the application", func() {
+			asc := libpak.NewAppSubdirContributor("../public", libcnb.LayerTypes{Launch: true}, bard.NewLogger(io.Discard))
+			asc.ApplicationPath = application
+
+			_, err := asc.Contribute(layer)
+			Expect(err).To(MatchError("path ../public must be relative to the application and within it"))
+		})
+	})
+
 	context("NewHelperLayer", func() {
 		it("returns a BOM entry with version equal to buildpack version", func() {
 			_, entry := libpak.NewHelperLayer(libcnb.Buildpack{
@@ -934,6 +1523,38 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "helper")))
 		})
 
+		it("links helpers to distinct sources", func() {
+			Expect(os.MkdirAll(filepath.Join(buildpack.Path, "bin", "other"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(buildpack.Path, "bin", "other", "helper"), []byte{}, 0755)).To(Succeed())
+
+			hlc = libpak.NewHelperLayerContributorWithSources(buildpack, map[string]string{
+				"test-name-1": "bin/helper",
+				"test-name-2": "bin/other/helper",
+			})
+
+			_, err := hlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			file := filepath.Join(layer.Exec.FilePath("test-name-1"))
+			Expect(file).To(BeAnExistingFile())
+			Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "helpers", "test-name-1")))
+
+			file = filepath.Join(layer.Exec.FilePath("test-name-2"))
+			Expect(file).To(BeAnExistingFile())
+			Expect(os.Readlink(file)).To(Equal(filepath.Join(layer.Path, "helpers", "test-name-2")))
+
+			artifact, err := hlc.AsSyftArtifact()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(artifact.Locations).To(Equal([]sbom.SyftLocation{
+				{Path: "helpers/test-name-1"},
+				{Path: "helpers/test-name-2"},
+			}))
+			Expect(artifact.CPEs).To(Equal([]string{
+				"cpe:2.3:a:test-id:test-name-1:test-version:*:*:*:*:*:*:*",
+				"cpe:2.3:a:test-id:test-name-2:test-version:*:*:*:*:*:*:*",
+			}))
+		})
+
 		it("does not call function with matching metadata", func() {
 			buildpackInfo := map[string]interface{}{
 				"id":          buildpack.Info.ID,
@@ -994,6 +1615,18 @@ func testLayer(t *testing.T, context spec.G, it spec.S) {
 			Expect(layer.LayerTypes.Build).To(BeFalse())
 		})
 
+		it("adds SBOM files in each format", func() {
+			layer.Metadata = map[string]interface{}{}
+			hlc.SBOMFormats = []libcnb.SBOMFormat{libcnb.SyftJSON, libcnb.CycloneDXJSON}
+
+			_, err := hlc.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))).To(ContainSubstring(`"Artifacts":[`))
+			Expect(os.ReadFile(layer.SBOMPath(libcnb.CycloneDXJSON))).To(ContainSubstring(`"purl":"pkg:generic/test-id@test-version"`))
+			Expect(layer.SBOMPath(libcnb.SPDXJSON)).NotTo(BeAnExistingFile())
+		})
+
 		it("adds expected Syft SBOM file", func() {
 			layer.Metadata = map[string]interface{}{}
 
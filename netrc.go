@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/paketo-buildpacks/libpak/internal"
+)
+
+// NetrcRequestModifier returns a RequestModifierFunc that applies basic authentication credentials for the request's
+// host from the netrc file at $NETRC or ~/.netrc.  The file is read each time a request is modified and a missing file
+// is ignored.
+func NetrcRequestModifier() RequestModifierFunc {
+	return func(request *http.Request) (*http.Request, error) {
+		path, err := internal.NetrcPath()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine netrc path\n%w", err)
+		}
+
+		n, err := internal.ParseNetrc(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s as netrc\n%w", path, err)
+		}
+
+		return n.BasicAuth(request)
+	}
+}
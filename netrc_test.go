@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+func testNetrc(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = filepath.Join(t.TempDir(), "netrc")
+		t.Setenv("NETRC", path)
+	})
+
+	it("applies basic auth for match", func() {
+		Expect(os.WriteFile(path, []byte(`machine test-machine login test-login password test-password`), 0644)).To(Succeed())
+
+		req, err := http.NewRequest("GET", "http://test-machine", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		req, err = libpak.NetrcRequestModifier()(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		u, p, ok := req.BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(u).To(Equal("test-login"))
+		Expect(p).To(Equal("test-password"))
+	})
+
+	it("does not apply auth if netrc does not exist", func() {
+		req, err := http.NewRequest("GET", "http://test-machine", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		req, err = libpak.NetrcRequestModifier()(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, ok := req.BasicAuth()
+		Expect(ok).To(BeFalse())
+	})
+}
@@ -1,14 +1,18 @@
 package sbom
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/buildpacks/libcnb"
 	"github.com/mitchellh/hashstructure/v2"
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/sherpa"
 )
 
 //go:generate mockery -name SBOMScanner -case=underscore
@@ -50,7 +54,7 @@ func (s SyftDependency) WriteTo(path string) error {
 		return fmt.Errorf("unable to marshal to JSON\n%w", err)
 	}
 
-	err = os.WriteFile(path, output, 0644)
+	err = sherpa.WriteFileAtomic(path, output, 0644)
 	if err != nil {
 		return fmt.Errorf("unable to write to path %s\n%w", path, err)
 	}
@@ -58,6 +62,594 @@ func (s SyftDependency) WriteTo(path string) error {
 	return nil
 }
 
+type cycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Component *cycloneDXComponent `json:"component,omitempty"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Type     string             `json:"type"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	CPE      string             `json:"cpe,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Licenses []cycloneDXLicense `json:"licenses,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	License    *cycloneDXLicenseID `json:"license,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+}
+
+type cycloneDXLicenseID struct {
+	ID string `json:"id"`
+}
+
+// WriteCycloneDXTo writes the artifacts as a minimal CycloneDX 1.4 JSON document to path, without invoking syft.  The
+// document contains no timestamp or serial number so that it is reproducible.
+func (s SyftDependency) WriteCycloneDXTo(path string) error {
+	doc := cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Components:  []cycloneDXComponent{},
+	}
+
+	if s.Source.Target != "" {
+		doc.Metadata.Component = &cycloneDXComponent{Type: "file", Name: s.Source.Target}
+	}
+
+	for _, artifact := range s.Artifacts {
+		ref := artifact.ID
+		if ref == "" {
+			h, err := artifact.Hash()
+			if err != nil {
+				return fmt.Errorf("unable to hash artifact %s\n%w", artifact.Name, err)
+			}
+			ref = h
+		}
+
+		c := cycloneDXComponent{
+			BOMRef:  ref,
+			Type:    "library",
+			Name:    artifact.Name,
+			Version: artifact.Version,
+			PURL:    artifact.PURL,
+		}
+
+		if len(artifact.CPEs) > 0 {
+			c.CPE = artifact.CPEs[0]
+		}
+
+		for _, l := range artifact.Licenses {
+			if strings.Contains(l, " ") {
+				c.Licenses = append(c.Licenses, cycloneDXLicense{Expression: l})
+			} else {
+				c.Licenses = append(c.Licenses, cycloneDXLicense{License: &cycloneDXLicenseID{ID: l}})
+			}
+		}
+
+		doc.Components = append(doc.Components, c)
+	}
+
+	output, err := json.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+
+	err = sherpa.WriteFileAtomic(path, output, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write to path %s\n%w", path, err)
+	}
+
+	return nil
+}
+
+type spdxDocument struct {
+	SPDXVersion       string           `json:"spdxVersion"`
+	DataLicense       string           `json:"dataLicense"`
+	SPDXID            string           `json:"SPDXID"`
+	Name              string           `json:"name"`
+	DocumentNamespace string           `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo `json:"creationInfo"`
+	Packages          []spdxPackage    `json:"packages"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// spdxInvalidID matches the characters that are not allowed in an SPDX identifier.
+var spdxInvalidID = regexp.MustCompile(`[^a-zA-Z0-9.-]`)
+
+// WriteSPDXTo writes the artifacts as a minimal SPDX 2.2 JSON document to path, without invoking syft.  The document
+// has a fixed creation time and a namespace derived from its packages so that it is reproducible.
+func (s SyftDependency) WriteSPDXTo(path string) error {
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.2",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        s.Source.Target,
+		CreationInfo: spdxCreationInfo{
+			Created:  "1980-01-01T00:00:01Z",
+			Creators: []string{"Tool: libpak"},
+		},
+		Packages: []spdxPackage{},
+	}
+
+	for _, artifact := range s.Artifacts {
+		ref := artifact.ID
+		if ref == "" {
+			h, err := artifact.Hash()
+			if err != nil {
+				return fmt.Errorf("unable to hash artifact %s\n%w", artifact.Name, err)
+			}
+			ref = h
+		}
+
+		license := "NOASSERTION"
+		if len(artifact.Licenses) > 0 {
+			license = strings.Join(artifact.Licenses, " AND ")
+		}
+
+		p := spdxPackage{
+			Name:             artifact.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%s", spdxInvalidID.ReplaceAllString(ref, "-")),
+			VersionInfo:      artifact.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  license,
+			CopyrightText:    "NOASSERTION",
+		}
+
+		for _, c := range artifact.CPEs {
+			p.ExternalRefs = append(p.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     "cpe23Type",
+				ReferenceLocator:  c,
+			})
+		}
+
+		if artifact.PURL != "" {
+			p.ExternalRefs = append(p.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "PACKAGE_MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  artifact.PURL,
+			})
+		}
+
+		doc.Packages = append(doc.Packages, p)
+	}
+
+	packages, err := json.Marshal(doc.Packages)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+	doc.DocumentNamespace = fmt.Sprintf("https://paketo.io/spdx/%x", sha256.Sum256(packages))
+
+	output, err := json.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+
+	err = sherpa.WriteFileAtomic(path, output, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write to path %s\n%w", path, err)
+	}
+
+	return nil
+}
+
+// WriteFormatTo writes the artifacts to path in the given format, one of libcnb.SyftJSON, libcnb.CycloneDXJSON, or
+// libcnb.SPDXJSON.
+func (s SyftDependency) WriteFormatTo(path string, format libcnb.SBOMFormat) error {
+	switch format {
+	case libcnb.SyftJSON:
+		return s.WriteTo(path)
+	case libcnb.CycloneDXJSON:
+		return s.WriteCycloneDXTo(path)
+	case libcnb.SPDXJSON:
+		return s.WriteSPDXTo(path)
+	default:
+		return fmt.Errorf("unsupported SBOM format %d", format)
+	}
+}
+
+// MergeCycloneDX combines the components of the CycloneDX JSON documents at srcs and writes them to dst as a single
+// document.  If dst already contains a CycloneDX JSON document, its components are retained.  Components are
+// deduplicated by bom-ref, or by PURL when a component has no bom-ref, and dependencies are deduplicated by ref.  All
+// other content is taken from the first document, without a serial number or timestamp so that the result is
+// reproducible.  Sources that do not exist are ignored.
+func MergeCycloneDX(dst string, srcs ...string) error {
+	paths := srcs
+	if _, err := os.Stat(dst); err == nil {
+		paths = append([]string{dst}, srcs...)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("unable to stat %s\n%w", dst, err)
+	}
+
+	var (
+		merged       map[string]interface{}
+		components   = []interface{}{}
+		dependencies []interface{}
+		seen         = map[string]bool{}
+		seenRefs     = map[string]bool{}
+	)
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		doc, err := loadCycloneDXFile(path)
+		if err != nil {
+			return err
+		}
+
+		if merged == nil {
+			merged = doc
+		}
+
+		c, _ := doc["components"].([]interface{})
+		for _, component := range c {
+			m, _ := component.(map[string]interface{})
+			ref, _ := m["bom-ref"].(string)
+			purl, _ := m["purl"].(string)
+
+			if (ref != "" && seen["bom-ref:"+ref]) || (purl != "" && seen["purl:"+purl]) {
+				continue
+			}
+			if ref != "" {
+				seen["bom-ref:"+ref] = true
+			}
+			if purl != "" {
+				seen["purl:"+purl] = true
+			}
+
+			components = append(components, component)
+		}
+
+		d, _ := doc["dependencies"].([]interface{})
+		for _, dependency := range d {
+			m, _ := dependency.(map[string]interface{})
+			ref, _ := m["ref"].(string)
+
+			if ref != "" && seenRefs[ref] {
+				continue
+			}
+			seenRefs[ref] = true
+
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	if merged == nil {
+		return nil
+	}
+
+	delete(merged, "serialNumber")
+	if metadata, ok := merged["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "timestamp")
+	}
+
+	merged["components"] = components
+	if dependencies != nil {
+		merged["dependencies"] = dependencies
+	}
+
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("unable to encode CycloneDX JSON %s\n%w", dst, err)
+	}
+
+	if err := sherpa.WriteFileAtomic(dst, b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", dst, err)
+	}
+
+	return nil
+}
+
+// MergeSyftDependencies combines the artifacts of deps and writes them to path as a single Syft JSON document.  If path
+// already contains a Syft JSON document, its artifacts are retained.  Artifacts are deduplicated by ID, or by Hash when
+// an artifact has no ID.  The source, descriptor, and schema are taken from the first document.
+func MergeSyftDependencies(path string, deps ...SyftDependency) error {
+	if b, err := os.ReadFile(path); err == nil {
+		var existing SyftDependency
+		if err := json.Unmarshal(b, &existing); err != nil {
+			return fmt.Errorf("unable to decode Syft JSON %s\n%w", path, err)
+		}
+		deps = append([]SyftDependency{existing}, deps...)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	if len(deps) == 0 {
+		return nil
+	}
+
+	merged := deps[0]
+	merged.Artifacts = nil
+
+	seen := map[string]bool{}
+	for _, dep := range deps {
+		for _, artifact := range dep.Artifacts {
+			id := artifact.ID
+			if id == "" {
+				h, err := artifact.Hash()
+				if err != nil {
+					return fmt.Errorf("unable to hash artifact %s\n%w", artifact.Name, err)
+				}
+				id = h
+			}
+
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			merged.Artifacts = append(merged.Artifacts, artifact)
+		}
+	}
+
+	return merged.WriteTo(path)
+}
+
+// Deduplicate removes artifacts from the Syft JSON documents at paths that already appear in an earlier document, or
+// earlier in the same document, so that a dependency contributed by several layers is only reported once.  Artifacts
+// are identified by their ID which, for artifacts created by libpak, is SyftArtifact.Hash().  Artifacts without an ID
+// are identified by SyftArtifact.Hash().  All other content of the documents is preserved and paths that do not exist
+// are ignored.
+func Deduplicate(paths ...string) error {
+	seen := map[string]bool{}
+
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", path, err)
+		}
+
+		raw := map[string]interface{}{}
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return fmt.Errorf("unable to decode Syft JSON %s\n%w", path, err)
+		}
+
+		key := "artifacts"
+		if _, ok := raw[key]; !ok {
+			key = "Artifacts"
+		}
+
+		artifacts, ok := raw[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		var kept []interface{}
+		for _, a := range artifacts {
+			id, err := artifactID(a)
+			if err != nil {
+				return fmt.Errorf("unable to identify artifact in %s\n%w", path, err)
+			}
+
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			kept = append(kept, a)
+		}
+
+		if len(kept) == len(artifacts) {
+			continue
+		}
+
+		if kept == nil {
+			kept = []interface{}{}
+		}
+		raw[key] = kept
+
+		b, err = json.Marshal(raw)
+		if err != nil {
+			return fmt.Errorf("unable to encode Syft JSON %s\n%w", path, err)
+		}
+
+		if err := sherpa.WriteFileAtomic(path, b, 0644); err != nil {
+			return fmt.Errorf("unable to write %s\n%w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// RelocateSyft rewrites the Syft JSON document at path so that artifact location paths and the source target that
+// are oldPrefix, or are within oldPrefix, are within newPrefix instead.  It is used when files described by an SBOM
+// are moved, for example from one layer to another.  All other content of the document is preserved.
+func RelocateSyft(path string, oldPrefix string, newPrefix string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("unable to decode Syft JSON %s\n%w", path, err)
+	}
+
+	relocate := func(m map[string]interface{}, key string) {
+		k, v, ok := syftField(m, key)
+		if !ok {
+			return
+		}
+
+		if p, ok := v.(string); ok {
+			m[k] = relocatePath(p, oldPrefix, newPrefix)
+		}
+	}
+
+	if _, artifacts, ok := syftField(raw, "artifacts"); ok {
+		artifacts, _ := artifacts.([]interface{})
+		for _, a := range artifacts {
+			a, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			_, locations, _ := syftField(a, "locations")
+			l, _ := locations.([]interface{})
+			for _, location := range l {
+				if location, ok := location.(map[string]interface{}); ok {
+					relocate(location, "path")
+				}
+			}
+		}
+	}
+
+	if _, source, ok := syftField(raw, "source"); ok {
+		if source, ok := source.(map[string]interface{}); ok {
+			relocate(source, "target")
+		}
+	}
+
+	b, err = json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("unable to encode Syft JSON %s\n%w", path, err)
+	}
+
+	if err := sherpa.WriteFileAtomic(path, b, 0644); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", path, err)
+	}
+
+	return nil
+}
+
+// syftField returns the key and value of the field of a decoded Syft JSON object, which is lower case in documents
+// written by Syft and title case in documents written by libpak.
+func syftField(m map[string]interface{}, key string) (string, interface{}, bool) {
+	for _, k := range []string{key, strings.ToUpper(key[:1]) + key[1:]} {
+		if v, ok := m[k]; ok {
+			return k, v, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// relocatePath returns path with oldPrefix replaced by newPrefix if path is, or is within, oldPrefix.
+func relocatePath(path string, oldPrefix string, newPrefix string) string {
+	oldPrefix = strings.TrimSuffix(oldPrefix, "/")
+
+	if path == oldPrefix {
+		return newPrefix
+	}
+
+	if strings.HasPrefix(path, oldPrefix+"/") {
+		return strings.TrimSuffix(newPrefix, "/") + strings.TrimPrefix(path, oldPrefix)
+	}
+
+	return path
+}
+
+// artifactID returns the id of a decoded Syft JSON artifact, or the SyftArtifact.Hash() of it if it has no id.
+func artifactID(artifact interface{}) (string, error) {
+	if m, ok := artifact.(map[string]interface{}); ok {
+		for _, k := range []string{"id", "ID"} {
+			if id, ok := m[k].(string); ok && id != "" {
+				return id, nil
+			}
+		}
+	}
+
+	b, err := json.Marshal(artifact)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode artifact\n%w", err)
+	}
+
+	var a SyftArtifact
+	if err := json.Unmarshal(b, &a); err != nil {
+		return "", fmt.Errorf("unable to decode artifact\n%w", err)
+	}
+
+	return a.Hash()
+}
+
+// Validate checks that the Syft JSON document at path is fit to ship.  Each artifact must have a name, a version, a
+// PURL of the form pkg:type/name, and a non-empty FoundBy, and may not have empty or bare LicenseRef- licenses.  All
+// problems found are reported in a single error.
+func Validate(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	var dep SyftDependency
+	if err := json.Unmarshal(b, &dep); err != nil {
+		return fmt.Errorf("unable to decode Syft JSON %s\n%w", path, err)
+	}
+
+	var problems []string
+	for i, artifact := range dep.Artifacts {
+		name := artifact.Name
+		if name == "" {
+			name = fmt.Sprintf("artifact %d", i)
+			problems = append(problems, fmt.Sprintf("%s: name must be set", name))
+		}
+
+		if artifact.Version == "" {
+			problems = append(problems, fmt.Sprintf("%s: version must be set", name))
+		}
+
+		if artifact.PURL == "" {
+			problems = append(problems, fmt.Sprintf("%s: purl must be set", name))
+		} else if !strings.HasPrefix(artifact.PURL, "pkg:") || !strings.Contains(artifact.PURL, "/") {
+			problems = append(problems, fmt.Sprintf("%s: purl %q is not of the form pkg:type/name@version", name, artifact.PURL))
+		}
+
+		if artifact.FoundBy == "" {
+			problems = append(problems, fmt.Sprintf("%s: found by must be set", name))
+		}
+
+		for _, l := range artifact.Licenses {
+			if l := strings.TrimSpace(l); l == "" || l == "LicenseRef-" {
+				problems = append(problems, fmt.Sprintf("%s: license %q is empty", name, l))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in %s\n%s", len(problems), path, strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
 type SyftArtifact struct {
 	ID        string
 	Name      string
@@ -120,39 +712,46 @@ func NewSyftCLISBOMScanner(layers libcnb.Layers, executor effect.Executor, logge
 func (b SyftCLISBOMScanner) ScanLayer(layer libcnb.Layer, scanDir string, formats ...libcnb.SBOMFormat) error {
 	return b.scan(func(fmt libcnb.SBOMFormat) string {
 		return layer.SBOMPath(fmt)
-	}, scanDir, formats...)
+	}, fmt.Sprintf("dir:%s", scanDir), formats...)
 }
 
 // ScanBuild will use syft CLI to scan the scanDir and write it's output to the build SBoM file in the given formats
 func (b SyftCLISBOMScanner) ScanBuild(scanDir string, formats ...libcnb.SBOMFormat) error {
 	return b.scan(func(fmt libcnb.SBOMFormat) string {
 		return b.Layers.BuildSBOMPath(fmt)
-	}, scanDir, formats...)
+	}, fmt.Sprintf("dir:%s", scanDir), formats...)
 }
 
 // ScanLaunch will use syft CLI to scan the scanDir and write it's output to the launch SBoM file in the given formats
 func (b SyftCLISBOMScanner) ScanLaunch(scanDir string, formats ...libcnb.SBOMFormat) error {
 	return b.scan(func(fmt libcnb.SBOMFormat) string {
 		return b.Layers.LaunchSBOMPath(fmt)
-	}, scanDir, formats...)
+	}, fmt.Sprintf("dir:%s", scanDir), formats...)
+}
+
+// ScanFile will use syft CLI to scan the single file at path and write it's output to the layer SBoM file in the given
+// formats
+func (b SyftCLISBOMScanner) ScanFile(path string, layer libcnb.Layer, formats ...libcnb.SBOMFormat) error {
+	return b.scan(func(fmt libcnb.SBOMFormat) string {
+		return layer.SBOMPath(fmt)
+	}, fmt.Sprintf("file:%s", path), formats...)
 }
 
-func (b SyftCLISBOMScanner) scan(sbomPathCreator func(libcnb.SBOMFormat) string, scanDir string, formats ...libcnb.SBOMFormat) error {
-	args := []string{"scan", "-q"}
+// ScanSPDXTagValue will use syft CLI to scan the scanDir and write an SPDX tag-value document to path.  SPDX tag-value
+// is not one of the SBOM formats of the buildpack specification, so the document is written to path rather than to a
+// layer, build, or launch SBOM file, for example for a compliance pipeline to collect.
+func (b SyftCLISBOMScanner) ScanSPDXTagValue(scanDir string, path string) error {
+	return b.syft([]string{"-o", fmt.Sprintf("spdx-tag-value=%s", path)}, fmt.Sprintf("dir:%s", scanDir))
+}
 
+func (b SyftCLISBOMScanner) scan(sbomPathCreator func(libcnb.SBOMFormat) string, source string, formats ...libcnb.SBOMFormat) error {
+	var outputs []string
 	for _, format := range formats {
-		args = append(args, "-o", fmt.Sprintf("%s=%s", SBOMFormatToSyftOutputFormat(format), sbomPathCreator(format)))
+		outputs = append(outputs, "-o", fmt.Sprintf("%s=%s", SBOMFormatToSyftOutputFormat(format), sbomPathCreator(format)))
 	}
 
-	args = append(args, fmt.Sprintf("dir:%s", scanDir))
-
-	if err := b.Executor.Execute(effect.Execution{
-		Command: "syft",
-		Args:    args,
-		Stdout:  b.Logger.TerminalErrorWriter(),
-		Stderr:  b.Logger.TerminalErrorWriter(),
-	}); err != nil {
-		return fmt.Errorf("unable to run `syft %s`\n%w", args, err)
+	if err := b.syft(outputs, source); err != nil {
+		return err
 	}
 
 	// cleans cyclonedx file which has a timestamp and unique id which always change
@@ -167,6 +766,24 @@ func (b SyftCLISBOMScanner) scan(sbomPathCreator func(libcnb.SBOMFormat) string,
 	return nil
 }
 
+// syft runs syft to scan source with the given output arguments.
+func (b SyftCLISBOMScanner) syft(outputs []string, source string) error {
+	args := append(append([]string{"scan", "-q"}, outputs...), source)
+
+	stdout, stderr, err := effect.ExecuteWithOutput(b.Executor, effect.Execution{
+		Command: "syft",
+		Args:    args,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to run `syft %s`\n%s%w", args, stdout+stderr, err)
+	}
+	if output := stdout + stderr; output != "" {
+		b.Logger.Debug(output)
+	}
+
+	return nil
+}
+
 func (b SyftCLISBOMScanner) makeCycloneDXReproducible(path string) error {
 	input, err := loadCycloneDXFile(path)
 	if err != nil {
@@ -1,6 +1,8 @@
 package sbom_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -71,6 +73,18 @@ func testSBOM(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(result)).To(Equal("succeed1"))
 		})
 
+		it("returns the output of syft when it fails", func() {
+			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
+				e := args.Get(0).(effect.Execution)
+				_, _ = e.Stderr.Write([]byte("test-syft-error\n"))
+			}).Return(fmt.Errorf("exit status 1"))
+
+			scanner = sbom.NewSyftCLISBOMScanner(layers, &executor, bard.NewLogger(io.Discard))
+
+			err := scanner.ScanBuild("something", libcnb.SyftJSON)
+			Expect(err).To(MatchError(ContainSubstring("test-syft-error\nexit status 1")))
+		})
+
 		it("runs syft to generate reproducible cycloneDX JSON", func() {
 			format := libcnb.CycloneDXJSON
 			outputPath := layers.BuildSBOMPath(format)
@@ -143,6 +157,32 @@ func testSBOM(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(result)).To(Equal("succeed2"))
 		})
 
+		it("runs syft once to generate layer-specific JSON for a single file", func() {
+			format := libcnb.SyftJSON
+			outputPath := layer.SBOMPath(format)
+
+			executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+				return e.Command == "syft" &&
+					len(e.Args) == 5 &&
+					strings.HasPrefix(e.Args[3], "json=") &&
+					e.Args[4] == "file:something.jar"
+			})).Run(func(args mock.Arguments) {
+				Expect(os.WriteFile(outputPath, []byte("succeed3"), 0644)).To(Succeed())
+			}).Return(nil)
+
+			scanner := sbom.SyftCLISBOMScanner{
+				Executor: &executor,
+				Layers:   layers,
+				Logger:   bard.NewLogger(io.Discard),
+			}
+
+			Expect(scanner.ScanFile("something.jar", layer, format)).To(Succeed())
+
+			result, err := os.ReadFile(outputPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(result)).To(Equal("succeed3"))
+		})
+
 		it("runs syft once for all three formats", func() {
 			executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
 				return e.Command == "syft" &&
@@ -178,6 +218,78 @@ func testSBOM(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(result)).To(HavePrefix(`{"succeed":3}`))
 		})
 
+		it("runs syft to generate SPDX tag-value", func() {
+			outputPath := filepath.Join(t.TempDir(), "test.spdx")
+
+			executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+				return e.Command == "syft" &&
+					len(e.Args) == 5 &&
+					e.Args[3] == fmt.Sprintf("spdx-tag-value=%s", outputPath) &&
+					e.Args[4] == "dir:something"
+			})).Return(nil)
+
+			scanner := sbom.SyftCLISBOMScanner{
+				Executor: &executor,
+				Layers:   layers,
+				Logger:   bard.NewLogger(io.Discard),
+			}
+
+			Expect(scanner.ScanSPDXTagValue("something", outputPath)).To(Succeed())
+			executor.AssertExpectations(t)
+		})
+
+		it("relocates paths", func() {
+			path := filepath.Join(layers.Path, "test.sbom.syft.json")
+
+			Expect(sbom.NewSyftDependency("/layers/old", []sbom.SyftArtifact{
+				{ID: "test-id", Name: "test-name", Locations: []sbom.SyftLocation{{Path: "/layers/old/bin/test"}, {Path: "/layers/older/bin/test"}}},
+			}).WriteTo(path)).To(Succeed())
+
+			Expect(sbom.RelocateSyft(path, "/layers/old", "/layers/new")).To(Succeed())
+
+			var dep sbom.SyftDependency
+			b, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(b, &dep)).To(Succeed())
+
+			Expect(dep.Source.Target).To(Equal("/layers/new"))
+			Expect(dep.Artifacts[0].Locations).To(Equal([]sbom.SyftLocation{{Path: "/layers/new/bin/test"}, {Path: "/layers/older/bin/test"}}))
+		})
+
+		it("relocates paths in Syft documents", func() {
+			path := filepath.Join(layers.Path, "test.sbom.syft.json")
+			Expect(os.WriteFile(path, []byte(`{"artifacts":[{"id":"test-id","locations":[{"path":"/layers/old/bin/test","layerID":"test-layer"}]}],"source":{"target":"/layers/old","type":"directory"}}`), 0644)).To(Succeed())
+
+			Expect(sbom.RelocateSyft(path, "/layers/old/", "/layers/new")).To(Succeed())
+
+			b, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{"artifacts":[{"id":"test-id","locations":[{"layerID":"test-layer","path":"/layers/new/bin/test"}]}],"source":{"target":"/layers/new","type":"directory"}}`))
+		})
+
+		it("deduplicates artifacts across documents", func() {
+			first := filepath.Join(layers.Path, "first.sbom.syft.json")
+			second := filepath.Join(layers.Path, "second.sbom.syft.json")
+
+			Expect(sbom.NewSyftDependency("first", []sbom.SyftArtifact{
+				{ID: "shared", Name: "test-shared"},
+				{ID: "only-first", Name: "test-first"},
+			}).WriteTo(first)).To(Succeed())
+			Expect(os.WriteFile(second, []byte(`{"artifacts":[{"id":"shared","name":"test-shared","extra":true},{"id":"only-second","name":"test-second"}],"schema":{"version":"test"}}`), 0644)).To(Succeed())
+
+			Expect(sbom.Deduplicate(first, second, filepath.Join(layers.Path, "missing.json"))).To(Succeed())
+
+			var dep sbom.SyftDependency
+			b, err := os.ReadFile(first)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(b, &dep)).To(Succeed())
+			Expect(dep.Artifacts).To(HaveLen(2))
+
+			b, err = os.ReadFile(second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{"artifacts":[{"id":"only-second","name":"test-second"}],"schema":{"version":"test"}}`))
+		})
+
 		it("writes out a manual BOM entry", func() {
 			dep := sbom.SyftDependency{
 				Artifacts: []sbom.SyftArtifact{
@@ -258,6 +370,228 @@ func testSBOM(t *testing.T, context spec.G, it spec.S) {
 			Expect(string(data)).To(ContainSubstring(`"Descriptor":{`))
 			Expect(string(data)).To(ContainSubstring(`"Source":{`))
 		})
+
+		it("merges multiple dependencies", func() {
+			a := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{ID: "1234", Name: "test-dep-1", Version: "1.2.3"},
+				{Name: "test-dep-2", Version: "2.3.4"},
+			})
+			b := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{ID: "1234", Name: "test-dep-1", Version: "1.2.3"},
+				{Name: "test-dep-2", Version: "2.3.4"},
+				{ID: "5678", Name: "test-dep-3", Version: "3.4.5"},
+			})
+
+			outputFile := filepath.Join(layers.Path, "test-bom.json")
+			Expect(sbom.MergeSyftDependencies(outputFile, a, b)).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			var merged sbom.SyftDependency
+			Expect(json.Unmarshal(data, &merged)).To(Succeed())
+			Expect(merged.Artifacts).To(HaveLen(3))
+			Expect(merged.Artifacts[0].Name).To(Equal("test-dep-1"))
+			Expect(merged.Artifacts[1].Name).To(Equal("test-dep-2"))
+			Expect(merged.Artifacts[2].Name).To(Equal("test-dep-3"))
+			Expect(merged.Schema.Version).To(Equal("1.1.0"))
+		})
+
+		it("merges with an existing file", func() {
+			outputFile := filepath.Join(layers.Path, "test-bom.json")
+			Expect(sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{ID: "1234", Name: "test-dep-1", Version: "1.2.3"},
+			}).WriteTo(outputFile)).To(Succeed())
+
+			Expect(sbom.MergeSyftDependencies(outputFile, sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{ID: "5678", Name: "test-dep-2", Version: "2.3.4"},
+			}))).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			var merged sbom.SyftDependency
+			Expect(json.Unmarshal(data, &merged)).To(Succeed())
+			Expect(merged.Artifacts).To(HaveLen(2))
+			Expect(merged.Artifacts[0].Name).To(Equal("test-dep-1"))
+			Expect(merged.Artifacts[1].Name).To(Equal("test-dep-2"))
+		})
+
+		it("writes out a CycloneDX BOM entry", func() {
+			dep := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{
+					ID:       "1234",
+					Name:     "test-dep",
+					Version:  "1.2.3",
+					Type:     "UnknownPackage",
+					FoundBy:  "java-buildpack",
+					Licenses: []string{"Apache-2.0", "GPL-2.0 WITH Classpath-exception-2.0"},
+					CPEs: []string{
+						"cpe:2.3:a:some:jre:11.0.2:*:*:*:*:*:*:*",
+					},
+					PURL: "pkg:generic/some-java11@11.0.2?arch=amd64",
+				},
+			})
+
+			outputFile := filepath.Join(layers.Path, "test-bom.cdx.json")
+			Expect(dep.WriteCycloneDXTo(outputFile)).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"bomFormat":"CycloneDX","specVersion":"1.4","version":1,` +
+				`"metadata":{"component":{"type":"file","name":"path/to/layer"}},` +
+				`"components":[{"bom-ref":"1234","type":"library","name":"test-dep","version":"1.2.3",` +
+				`"cpe":"cpe:2.3:a:some:jre:11.0.2:*:*:*:*:*:*:*","purl":"pkg:generic/some-java11@11.0.2?arch=amd64",` +
+				`"licenses":[{"license":{"id":"Apache-2.0"}},{"expression":"GPL-2.0 WITH Classpath-exception-2.0"}]}]}`))
+		})
+
+		it("writes out an SPDX BOM entry", func() {
+			dep := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{
+					ID:       "1234",
+					Name:     "test-dep",
+					Version:  "1.2.3",
+					Licenses: []string{"Apache-2.0"},
+					CPEs:     []string{"cpe:2.3:a:some:jre:11.0.2:*:*:*:*:*:*:*"},
+					PURL:     "pkg:generic/some-java11@11.0.2?arch=amd64",
+				},
+			})
+
+			outputFile := filepath.Join(layers.Path, "test-bom.spdx.json")
+			Expect(dep.WriteSPDXTo(outputFile)).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			var doc map[string]interface{}
+			Expect(json.Unmarshal(data, &doc)).To(Succeed())
+			Expect(doc["spdxVersion"]).To(Equal("SPDX-2.2"))
+			Expect(doc["name"]).To(Equal("path/to/layer"))
+			Expect(doc["documentNamespace"]).To(HavePrefix("https://paketo.io/spdx/"))
+			Expect(doc["packages"]).To(Equal([]interface{}{
+				map[string]interface{}{
+					"name":             "test-dep",
+					"SPDXID":           "SPDXRef-Package-1234",
+					"versionInfo":      "1.2.3",
+					"downloadLocation": "NOASSERTION",
+					"filesAnalyzed":    false,
+					"licenseConcluded": "NOASSERTION",
+					"licenseDeclared":  "Apache-2.0",
+					"copyrightText":    "NOASSERTION",
+					"externalRefs": []interface{}{
+						map[string]interface{}{
+							"referenceCategory": "SECURITY",
+							"referenceType":     "cpe23Type",
+							"referenceLocator":  "cpe:2.3:a:some:jre:11.0.2:*:*:*:*:*:*:*",
+						},
+						map[string]interface{}{
+							"referenceCategory": "PACKAGE_MANAGER",
+							"referenceType":     "purl",
+							"referenceLocator":  "pkg:generic/some-java11@11.0.2?arch=amd64",
+						},
+					},
+				},
+			}))
+
+			other := filepath.Join(layers.Path, "other.spdx.json")
+			Expect(dep.WriteSPDXTo(other)).To(Succeed())
+			Expect(os.ReadFile(other)).To(Equal(data))
+		})
+
+		it("writes out a BOM entry in each format", func() {
+			dep := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{{ID: "1234", Name: "test-dep"}})
+
+			for _, f := range []libcnb.SBOMFormat{libcnb.SyftJSON, libcnb.CycloneDXJSON, libcnb.SPDXJSON} {
+				outputFile := filepath.Join(layers.Path, fmt.Sprintf("test-bom.%s", f))
+				Expect(dep.WriteFormatTo(outputFile, f)).To(Succeed())
+				Expect(outputFile).To(BeARegularFile())
+			}
+
+			Expect(dep.WriteFormatTo(filepath.Join(layers.Path, "test-bom"), libcnb.UnknownFormat)).
+				To(MatchError(ContainSubstring("unsupported SBOM format")))
+		})
+
+		it("merges CycloneDX documents", func() {
+			first := filepath.Join(layers.Path, "first.cdx.json")
+			Expect(os.WriteFile(first, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.4","version":1,`+
+				`"serialNumber":"urn:uuid:test-serial","metadata":{"timestamp":"2020-01-01T00:00:00Z","component":{"type":"file","name":"first"}},`+
+				`"components":[{"bom-ref":"ref-1","type":"library","name":"test-dep-1","purl":"pkg:generic/test-dep-1@1.0.0"}],`+
+				`"dependencies":[{"ref":"ref-1","dependsOn":[]}]}`), 0644)).To(Succeed())
+
+			second := filepath.Join(layers.Path, "second.cdx.json")
+			Expect(os.WriteFile(second, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.4","version":1,`+
+				`"serialNumber":"urn:uuid:other-serial","metadata":{"component":{"type":"file","name":"second"}},`+
+				`"components":[{"bom-ref":"ref-1","type":"library","name":"test-dep-1"},`+
+				`{"bom-ref":"ref-3","type":"library","name":"test-dep-1-copy","purl":"pkg:generic/test-dep-1@1.0.0"},`+
+				`{"type":"library","name":"test-dep-2","purl":"pkg:generic/test-dep-2@2.0.0"}],`+
+				`"dependencies":[{"ref":"ref-1","dependsOn":["ref-3"]},{"ref":"ref-3","dependsOn":[]}]}`), 0644)).To(Succeed())
+
+			outputFile := filepath.Join(layers.Path, "merged.cdx.json")
+			Expect(sbom.MergeCycloneDX(outputFile, first, second, filepath.Join(layers.Path, "missing.cdx.json"))).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"bomFormat":"CycloneDX",` +
+				`"components":[{"bom-ref":"ref-1","name":"test-dep-1","purl":"pkg:generic/test-dep-1@1.0.0","type":"library"},` +
+				`{"name":"test-dep-2","purl":"pkg:generic/test-dep-2@2.0.0","type":"library"}],` +
+				`"dependencies":[{"dependsOn":[],"ref":"ref-1"},{"dependsOn":[],"ref":"ref-3"}],` +
+				`"metadata":{"component":{"name":"first","type":"file"}},"specVersion":"1.4","version":1}`))
+		})
+
+		it("merges CycloneDX documents with an existing file", func() {
+			dep := sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{{ID: "ref-1", Name: "test-dep-1"}})
+			outputFile := filepath.Join(layers.Path, "merged.cdx.json")
+			Expect(dep.WriteCycloneDXTo(outputFile)).To(Succeed())
+
+			other := filepath.Join(layers.Path, "other.cdx.json")
+			dep = sbom.NewSyftDependency("path/to/other", []sbom.SyftArtifact{{ID: "ref-2", Name: "test-dep-2"}})
+			Expect(dep.WriteCycloneDXTo(other)).To(Succeed())
+
+			Expect(sbom.MergeCycloneDX(outputFile, other)).To(Succeed())
+
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			var merged map[string]interface{}
+			Expect(json.Unmarshal(data, &merged)).To(Succeed())
+			Expect(merged["components"]).To(HaveLen(2))
+			Expect(merged["metadata"]).To(Equal(map[string]interface{}{
+				"component": map[string]interface{}{"type": "file", "name": "path/to/layer"},
+			}))
+		})
+
+		it("validates a BOM entry", func() {
+			outputFile := filepath.Join(layers.Path, "test-bom.json")
+			Expect(sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{
+					Name:     "test-dep",
+					Version:  "1.2.3",
+					FoundBy:  "java-buildpack",
+					Licenses: []string{"Apache-2.0"},
+					PURL:     "pkg:generic/test-dep@1.2.3",
+				},
+			}).WriteTo(outputFile)).To(Succeed())
+
+			Expect(sbom.Validate(outputFile)).To(Succeed())
+		})
+
+		it("reports problems with an invalid BOM entry", func() {
+			outputFile := filepath.Join(layers.Path, "test-bom.json")
+			Expect(sbom.NewSyftDependency("path/to/layer", []sbom.SyftArtifact{
+				{
+					Name:     "test-dep",
+					Licenses: []string{"", "LicenseRef-"},
+					PURL:     "test-purl",
+				},
+			}).WriteTo(outputFile)).To(Succeed())
+
+			err := sbom.Validate(outputFile)
+			Expect(err).To(MatchError(ContainSubstring("found 5 problems")))
+			Expect(err).To(MatchError(ContainSubstring("test-dep: version must be set")))
+			Expect(err).To(MatchError(ContainSubstring(`test-dep: purl "test-purl" is not of the form pkg:type/name@version`)))
+			Expect(err).To(MatchError(ContainSubstring("test-dep: found by must be set")))
+			Expect(err).To(MatchError(ContainSubstring(`test-dep: license "LicenseRef-" is empty`)))
+		})
 	})
 
 }
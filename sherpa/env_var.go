@@ -21,6 +21,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak/bard"
 )
 
 // AppendToEnvVar appends a collection of values to an env var separated by a delimiter. If the env var does not already
@@ -52,6 +55,58 @@ func GetEnvWithDefault(name string, def string) string {
 	return def
 }
 
+// GetEnvIntWithDefault returns the value of an environment variable parsed as an int if it exists, otherwise returns
+// the default.  If the value cannot be parsed, the default is returned and the problem is logged at debug level.
+func GetEnvIntWithDefault(name string, def int) int {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		bard.NewLogger(os.Stdout).Debugf("unable to convert %s=%s to integer, using default %d\n%s", name, s, def, err)
+		return def
+	}
+
+	return i
+}
+
+// GetEnvBoolWithDefault returns the value of an environment variable parsed as a bool if it exists, otherwise returns
+// the default.  If the value cannot be parsed, the default is returned and the problem is logged at debug level.
+func GetEnvBoolWithDefault(name string, def bool) bool {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		bard.NewLogger(os.Stdout).Debugf("unable to convert %s=%s to bool, using default %t\n%s", name, s, def, err)
+		return def
+	}
+
+	return b
+}
+
+// GetEnvDurationWithDefault returns the value of an environment variable parsed as a time.Duration if it exists,
+// otherwise returns the default.  If the value cannot be parsed, the default is returned and the problem is logged at
+// debug level.
+func GetEnvDurationWithDefault(name string, def time.Duration) time.Duration {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return def
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		bard.NewLogger(os.Stdout).Debugf("unable to convert %s=%s to duration, using default %s\n%s", name, s, def, err)
+		return def
+	}
+
+	return d
+}
+
 // ResolveBool resolves a boolean value for a configuration option. Returns true for 1, t, T, TRUE, true, True. Returns
 // false for all other values or unset.
 func ResolveBool(name string) bool {
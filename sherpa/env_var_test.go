@@ -19,6 +19,7 @@ package sherpa_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
@@ -104,6 +105,54 @@ func testEnvVar(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("GetEnvIntWithDefault", func() {
+		it("returns value if set", func() {
+			t.Setenv("TEST_KEY", "42")
+			Expect(sherpa.GetEnvIntWithDefault("TEST_KEY", 1)).To(Equal(42))
+		})
+
+		it("returns default value if not set", func() {
+			Expect(sherpa.GetEnvIntWithDefault("ANOTHER_KEY", 1)).To(Equal(1))
+		})
+
+		it("returns default value if invalid", func() {
+			t.Setenv("TEST_KEY", "test-value")
+			Expect(sherpa.GetEnvIntWithDefault("TEST_KEY", 1)).To(Equal(1))
+		})
+	})
+
+	context("GetEnvBoolWithDefault", func() {
+		it("returns value if set", func() {
+			t.Setenv("TEST_KEY", "false")
+			Expect(sherpa.GetEnvBoolWithDefault("TEST_KEY", true)).To(BeFalse())
+		})
+
+		it("returns default value if not set", func() {
+			Expect(sherpa.GetEnvBoolWithDefault("ANOTHER_KEY", true)).To(BeTrue())
+		})
+
+		it("returns default value if invalid", func() {
+			t.Setenv("TEST_KEY", "test-value")
+			Expect(sherpa.GetEnvBoolWithDefault("TEST_KEY", true)).To(BeTrue())
+		})
+	})
+
+	context("GetEnvDurationWithDefault", func() {
+		it("returns value if set", func() {
+			t.Setenv("TEST_KEY", "1m30s")
+			Expect(sherpa.GetEnvDurationWithDefault("TEST_KEY", time.Second)).To(Equal(90 * time.Second))
+		})
+
+		it("returns default value if not set", func() {
+			Expect(sherpa.GetEnvDurationWithDefault("ANOTHER_KEY", time.Second)).To(Equal(time.Second))
+		})
+
+		it("returns default value if invalid", func() {
+			t.Setenv("TEST_KEY", "test-value")
+			Expect(sherpa.GetEnvDurationWithDefault("TEST_KEY", time.Second)).To(Equal(time.Second))
+		})
+	})
+
 	context("ResolveBoolErr", func() {
 		context("variable not set", func() {
 			it("returns false if not set", func() {
@@ -32,5 +32,6 @@ func TestUnit(t *testing.T) {
 	suite("FileListing", testFileListing)
 	suite("NodeJS", testNodeJS)
 	suite("Sherpa", testSherpa)
+	suite("WriteFile", testWriteFile)
 	suite.Run(t)
 }
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sherpa
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a file atomically.  The data is written to a temporary file in the same directory
+// which is then renamed to path, so that path never contains partially written data.  It ensures that the parent
+// directory is created.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", dir, err)
+	}
+
+	out, err := os.CreateTemp(dir, fmt.Sprintf(".%s-*", filepath.Base(path)))
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file in %s\n%w", dir, err)
+	}
+	defer os.Remove(out.Name())
+
+	if _, err := out.Write(data); err != nil {
+		out.Close()
+		return fmt.Errorf("unable to write %s\n%w", out.Name(), err)
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("unable to sync %s\n%w", out.Name(), err)
+	}
+
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("unable to close %s\n%w", out.Name(), err)
+	}
+
+	if err := os.Chmod(out.Name(), perm); err != nil {
+		return fmt.Errorf("unable to chmod %s\n%w", out.Name(), err)
+	}
+
+	if err := os.Rename(out.Name(), path); err != nil {
+		return fmt.Errorf("unable to rename %s to %s\n%w", out.Name(), path, err)
+	}
+
+	return nil
+}
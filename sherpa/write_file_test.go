@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sherpa_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+func testWriteFile(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = t.TempDir()
+	})
+
+	it("writes file", func() {
+		file := filepath.Join(path, "alpha", "test-file")
+
+		Expect(sherpa.WriteFileAtomic(file, []byte("test-content"), 0640)).To(Succeed())
+
+		Expect(os.ReadFile(file)).To(Equal([]byte("test-content")))
+		Expect(os.Stat(file)).To(HaveField("Mode()", os.FileMode(0640)))
+		Expect(os.ReadDir(filepath.Dir(file))).To(HaveLen(1))
+	})
+
+	it("replaces existing file", func() {
+		file := filepath.Join(path, "test-file")
+		Expect(os.WriteFile(file, []byte("old-content"), 0644)).To(Succeed())
+
+		Expect(sherpa.WriteFileAtomic(file, []byte("test-content"), 0644)).To(Succeed())
+
+		Expect(os.ReadFile(file)).To(Equal([]byte("test-content")))
+	})
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// VersionMatcher tests whether concrete versions satisfy a buildpack version pattern.  A pattern is either a semver
+// constraint, such as "17.*" or "~1.2", or a regular expression, such as `17\.[\d]+\.[\d]+`.  The pattern "latest" is
+// the constraint "*".
+type VersionMatcher struct {
+	constraint *semver.Constraints
+	expression *regexp.Regexp
+}
+
+// NewVersionMatcher creates a new VersionMatcher for pattern.  The pattern is a semver constraint if it can be parsed
+// as one, otherwise it is a regular expression.
+func NewVersionMatcher(pattern string) (VersionMatcher, error) {
+	if m, err := NewVersionConstraintMatcher(pattern); err == nil {
+		return m, nil
+	}
+
+	e, err := regexp.Compile(pattern)
+	if err != nil {
+		return VersionMatcher{}, fmt.Errorf("unable to parse %s as a version constraint or regular expression\n%w", pattern, err)
+	}
+
+	return VersionMatcher{expression: e}, nil
+}
+
+// NewVersionExpressionMatcher creates a new VersionMatcher for pattern, which is always a regular expression even if it
+// could also be parsed as a semver constraint.
+func NewVersionExpressionMatcher(pattern string) (VersionMatcher, error) {
+	e, err := regexp.Compile(pattern)
+	if err != nil {
+		return VersionMatcher{}, err
+	}
+
+	return VersionMatcher{expression: e}, nil
+}
+
+// NewVersionConstraintMatcher creates a new VersionMatcher for pattern, which must be a semver constraint.
+func NewVersionConstraintMatcher(pattern string) (VersionMatcher, error) {
+	if pattern == "latest" {
+		pattern = "*"
+	}
+
+	c, err := semver.NewConstraint(pattern)
+	if err != nil {
+		return VersionMatcher{}, err
+	}
+
+	return VersionMatcher{constraint: c}, nil
+}
+
+// IsConstraint indicates whether the pattern is a semver constraint rather than a regular expression.
+func (v VersionMatcher) IsConstraint() bool {
+	return v.constraint != nil
+}
+
+// Matches indicates whether version satisfies the pattern.  A version that is not valid semver never satisfies a
+// constraint.
+func (v VersionMatcher) Matches(version string) bool {
+	if v.expression != nil {
+		return v.expression.MatchString(version)
+	}
+
+	if v.constraint == nil {
+		return false
+	}
+
+	sv, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+
+	return v.constraint.Check(sv)
+}
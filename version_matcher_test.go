@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package libpak_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/libpak"
+)
+
+func testVersionMatcher(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("constraint", func() {
+		it("matches versions satisfying the constraint", func() {
+			m, err := libpak.NewVersionMatcher("17.*")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(m.IsConstraint()).To(BeTrue())
+			Expect(m.Matches("17.0.1")).To(BeTrue())
+			Expect(m.Matches("11.0.2")).To(BeFalse())
+			Expect(m.Matches("not-semver")).To(BeFalse())
+		})
+
+		it("treats latest as any stable version", func() {
+			m, err := libpak.NewVersionConstraintMatcher("latest")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(m.Matches("21.0.2")).To(BeTrue())
+			Expect(m.Matches("22.0.0-ea")).To(BeFalse())
+		})
+
+		it("fails with a pattern that is not a constraint", func() {
+			_, err := libpak.NewVersionConstraintMatcher(`17\.[\d]+`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	context("regular expression", func() {
+		it("matches versions matching the expression", func() {
+			m, err := libpak.NewVersionMatcher(`17\.[\d]+\.[\d]+`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(m.IsConstraint()).To(BeFalse())
+			Expect(m.Matches("17.0.1")).To(BeTrue())
+			Expect(m.Matches("11.0.2")).To(BeFalse())
+		})
+
+		it("always treats the pattern as an expression", func() {
+			m, err := libpak.NewVersionExpressionMatcher("2023.*")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(m.IsConstraint()).To(BeFalse())
+			Expect(m.Matches("2023-01-05")).To(BeTrue())
+			Expect(m.Matches("2022-12-31")).To(BeFalse())
+		})
+
+		it("fails with an invalid expression", func() {
+			_, err := libpak.NewVersionMatcher(`17\.[`)
+			Expect(err).To(MatchError(ContainSubstring("unable to parse 17\\.[ as a version constraint or regular expression")))
+		})
+	})
+}